@@ -190,6 +190,24 @@ func (c *CLI) Command(cmd string, params ...string) {
 	fmt.Println()
 }
 
+// QuotedCommand prints a command whose parts have already been quoted by the caller, e.g. for a shell. The prefix
+// (e.g. "cd dir &&") is printed before the command, unless empty.
+func (c *CLI) QuotedCommand(prefix, cmd string, params ...string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	fmt.Print(IconRunner)
+
+	if prefix != "" {
+		fmt.Print(" ")
+		_, _ = GetColorSecondary().Print(prefix)
+	}
+
+	fmt.Printf(" %v ", cmd)
+	_, _ = GetColorSecondary().Print(strings.Join(params, " "))
+	fmt.Println()
+}
+
 // NewTable creates a new table.
 func (c *CLI) NewTable(columnHeaders ...any) table.Table {
 	return table.
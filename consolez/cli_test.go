@@ -154,6 +154,20 @@ func (*CLISuite) TestCommand_Rel(g *WithT) {
 	g.Expect(errBuf).To(BeEmpty())
 }
 
+func (*CLISuite) TestQuotedCommand(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	consolez.DefaultCLI.QuotedCommand("", "cmd", "'p 1'", "p2")
+	consolez.DefaultCLI.QuotedCommand("cd dir && K=V", "/abs/cmd")
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal(fmt.Sprintf(
+		"%v cmd \x1b[2m'p 1' p2\x1b[0m\n%v \x1b[2mcd dir && K=V\x1b[0m /abs/cmd \x1b[2m\x1b[0m\n",
+		consolez.IconRunner, consolez.IconRunner)))
+	g.Expect(errBuf).To(BeEmpty())
+}
+
 func (*CLISuite) TestCommand_Abs(g *WithT) {
 	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
 	defer fixturez.ResetOutputCapture()
@@ -13,6 +13,7 @@ import (
 // GoTestPrinter implements a printer for "go test" output.
 type GoTestPrinter interface {
 	PrintLine(line string)
+	PrintErrorLine(line string)
 	PrintDone()
 }
 
@@ -56,6 +57,12 @@ func (p *goTestPrinter) PrintLine(line string) {
 	}
 }
 
+// PrintErrorLine implements the GoTestPrinter interface. It prints a line of standard error (e.g. a build error).
+func (p *goTestPrinter) PrintErrorLine(line string) {
+	_, _ = GetColorWarning().Print(line)
+	fmt.Print("\n")
+}
+
 // PrintDone prints a final line.
 func (p *goTestPrinter) PrintDone() {
 	fmt.Printf(
@@ -46,6 +46,7 @@ func (*GoTestPrinterSuite) TestGoTestPrinter(g *WithT) {
 	p.PrintLine("?   \tunexpected")
 	p.PrintLine("ok  \tpkgn\t1s\tcoverage: 100%")
 	p.PrintLine(fmt.Sprintf("FAIL\t%v\t1s", strings.Repeat("p", 1024)))
+	p.PrintErrorLine("# pkgn")
 	p.PrintDone()
 
 	outBuf, errBuf := fixturez.MustEndOutputCapture()
@@ -72,6 +73,7 @@ func (*GoTestPrinterSuite) TestGoTestPrinter(g *WithT) {
 		"?   \tunexpected",
 		"\x1b[32mPASS    pkgn                                                         1s        \x1b[0m",
 		"\x1b[91mFAIL    ...ppppppppppppppppppppppppppppppppppppppppppppppppppppppppp 1s        \x1b[0m",
+		"\x1b[33m# pkgn\x1b[0m",
 		"DONE    [SKIP: 1, PASS: 2]                                           0s        ",
 		"",
 	}, "\n")))
@@ -217,7 +217,9 @@ func MustRunGoTests(params *GoTestsParams) {
 	}
 
 	p := consolez.NewGoTestPrinter()
-	cmd.MustLines(p.PrintLine)
+	cmd.MustLineEvents(shellz.SplitLineEvents(
+		func(e *shellz.LineEvent) { p.PrintLine(e.Text) },
+		func(e *shellz.LineEvent) { p.PrintErrorLine(e.Text) }))
 	p.PrintDone()
 
 	coverageJSON := processGoCoverage(params)
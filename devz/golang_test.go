@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/ibrt/golang-lib/errorz"
 	"github.com/ibrt/golang-lib/filez"
 	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/memz"
 	"github.com/ibrt/golang-lib/shellz"
 	"github.com/ibrt/golang-lib/shellz/tshellz"
 )
@@ -148,6 +150,41 @@ func (*GoSuite) TestRunGoChecks(g *WithT, ctrl *gomock.Controller) {
 	g.Expect(errBuf).To(BeEmpty())
 }
 
+func (*GoSuite) TestRunGoChecks_Error(g *WithT) {
+	e := tshellz.NewFakeExecutor().
+		Handle(
+			tshellz.MatchArgs(regexp.MustCompile(`^go vet `)),
+			tshellz.Returns("", "vet: ./main.go:1:1: unreachable code\n", 1)).
+		Handle(
+			tshellz.MatchCommand("go"),
+			tshellz.Returns("", "", 0))
+
+	shellz.DefaultExecutor = e
+	defer shellz.RestoreDefaultExecutor()
+
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(true), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	g.Expect(func() {
+		devz.MustRunGoChecks(&devz.GoChecksParams{
+			AllPackages: []string{"./..."},
+		})
+	}).To(PanicWith(And(
+		MatchError("execution error: go vet ./...: exit status 1"),
+		WithTransform(func(err error) int {
+			eErr, _ := errorz.As[*shellz.ExecutionError](err)
+			return eErr.GetExitCode()
+		}, Equal(1)))))
+
+	history := e.GetHistory()
+	g.Expect(history[len(history)-1].GetArgs()).To(Equal([]string{"go", "vet", "./..."}))
+	g.Expect(memz.TransformSlice(history, func(call *tshellz.FakeCall) string { return call.Params[0] })).
+		To(ContainElements("mod", "generate", "fmt", "build", "run", "vet"))
+
+	_, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(errBuf).To(Equal("vet: ./main.go:1:1: unreachable code\n"))
+}
+
 func (*GoSuite) TestRunGoTests_SelectedPackages(g *WithT, ctrl *gomock.Controller) {
 	devz.GoToolGoCov.GetVersion()     // warm up
 	devz.GoToolGoCovHTML.GetVersion() // warm up
@@ -259,7 +296,7 @@ func (*GoSuite) TestRunGoTests_SelectedPackages(g *WithT, ctrl *gomock.Controlle
 		"[................go-tests] generating Go code...",
 		"🏃 go generate ./...",
 		"[................go-tests] running tests...",
-		fmt.Sprintf("🏃 go test -trimpath -race -failfast -shuffle=on -covermode=atomic -coverprofile=%v/coverage.out -count=1 -run=^test$ -v ./package", dirPath),
+		fmt.Sprintf("🏃 go test -trimpath -race -failfast -shuffle=on -covermode=atomic -coverprofile=%v/coverage.out -count=1 '-run=^test$' -v ./package", dirPath),
 		"DONE    [SKIP: 0, PASS: 0]                                           0s        ",
 		"[................go-tests] processing coverage...",
 		"DONE    [LOWC: 0, MEDC: 0, HIGC: 0]                                  100.0% [0/0]",
@@ -381,7 +418,7 @@ func (*GoSuite) TestRunGoTests_AllPackages(g *WithT, ctrl *gomock.Controller) {
 		"[................go-tests] generating Go code...",
 		"🏃 go generate ./...",
 		"[................go-tests] running tests...",
-		fmt.Sprintf("🏃 go test -trimpath -race -failfast -shuffle=on -covermode=atomic -coverprofile=%v/coverage.out -count=1 -run=^test$ ./...", dirPath),
+		fmt.Sprintf("🏃 go test -trimpath -race -failfast -shuffle=on -covermode=atomic -coverprofile=%v/coverage.out -count=1 '-run=^test$' ./...", dirPath),
 		"DONE    [SKIP: 0, PASS: 0]                                           0s        ",
 		"[................go-tests] processing coverage...",
 		"DONE    [LOWC: 0, MEDC: 0, HIGC: 0]                                  100.0% [0/0]",
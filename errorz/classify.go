@@ -0,0 +1,115 @@
+package errorz
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Known metadata keys used by the error classification options.
+const (
+	MetadataKeyHTTPStatus    = "errorz.httpStatus"
+	MetadataKeyCode          = "errorz.code"
+	MetadataKeyPublicMessage = "errorz.publicMessage"
+)
+
+// Default classification values.
+const (
+	DefaultHTTPStatus = http.StatusInternalServerError
+	DefaultCode       = "internal"
+)
+
+// HTTPStatusProvider describes an error which provides its own HTTP status.
+type HTTPStatusProvider interface {
+	GetHTTPStatus() int
+}
+
+// CodeProvider describes an error which provides its own machine-readable code.
+type CodeProvider interface {
+	GetCode() string
+}
+
+// PublicMessageProvider describes an error which provides its own public-safe message.
+type PublicMessageProvider interface {
+	GetPublicMessage() string
+}
+
+// WithHTTPStatus returns a WrapOption that attaches an HTTP status to the wrapped error.
+func WithHTTPStatus(httpStatus int) WrapOption {
+	return WithMetadata(MetadataKeyHTTPStatus, httpStatus)
+}
+
+// WithCode returns a WrapOption that attaches a machine-readable code to the wrapped error.
+func WithCode(code string) WrapOption {
+	return WithMetadata(MetadataKeyCode, code)
+}
+
+// WithPublicMessage returns a WrapOption that attaches a public-safe message to the wrapped error.
+func WithPublicMessage(publicMessage string) WrapOption {
+	return WithMetadata(MetadataKeyPublicMessage, publicMessage)
+}
+
+// Classification describes how an error should be presented to clients.
+type Classification struct {
+	HTTPStatus    int    `json:"httpStatus"`
+	Code          string `json:"code"`
+	PublicMessage string `json:"publicMessage"`
+}
+
+// Classify resolves the effective classification of the error by walking its chain, outermost error first. Each
+// property is resolved independently, either from the classification options or from an error implementing the
+// corresponding provider interface. Missing properties are derived from the HTTP status, which defaults to 500.
+// The public message never includes the error message: it defaults to the HTTP status text.
+// It returns nil if the error is nil.
+func Classify(err error) *Classification {
+	if err == nil {
+		return nil
+	}
+
+	c := &Classification{}
+
+	walk(err, func(err error) bool {
+		if e, ok := err.(*wrappedError); ok {
+			md := e.getMetadata()
+
+			if v, ok := md[MetadataKeyHTTPStatus].(int); ok && c.HTTPStatus == 0 {
+				c.HTTPStatus = v
+			}
+			if v, ok := md[MetadataKeyCode].(string); ok && c.Code == "" {
+				c.Code = v
+			}
+			if v, ok := md[MetadataKeyPublicMessage].(string); ok && c.PublicMessage == "" {
+				c.PublicMessage = v
+			}
+		}
+
+		if p, ok := err.(HTTPStatusProvider); ok && c.HTTPStatus == 0 {
+			c.HTTPStatus = p.GetHTTPStatus()
+		}
+		if p, ok := err.(CodeProvider); ok && c.Code == "" {
+			c.Code = p.GetCode()
+		}
+		if p, ok := err.(PublicMessageProvider); ok && c.PublicMessage == "" {
+			c.PublicMessage = p.GetPublicMessage()
+		}
+
+		return c.HTTPStatus == 0 || c.Code == "" || c.PublicMessage == ""
+	})
+
+	if http.StatusText(c.HTTPStatus) == "" {
+		c.HTTPStatus = DefaultHTTPStatus
+	}
+
+	if c.Code == "" {
+		if c.HTTPStatus >= http.StatusInternalServerError {
+			c.Code = DefaultCode
+		} else {
+			c.Code = strings.ToLower(strings.ReplaceAll(http.StatusText(c.HTTPStatus), " ", "_"))
+		}
+	}
+
+	if c.PublicMessage == "" {
+		c.PublicMessage = http.StatusText(c.HTTPStatus)
+	}
+
+	return c
+}
@@ -0,0 +1,126 @@
+package errorz_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+type classifiedError struct {
+	httpStatus    int
+	code          string
+	publicMessage string
+}
+
+func (e *classifiedError) Error() string {
+	return "classified error"
+}
+
+func (e *classifiedError) GetHTTPStatus() int {
+	return e.httpStatus
+}
+
+func (e *classifiedError) GetCode() string {
+	return e.code
+}
+
+func (e *classifiedError) GetPublicMessage() string {
+	return e.publicMessage
+}
+
+func TestClassify(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected *errorz.Classification
+	}
+
+	for i, tc := range []testCase{
+		{
+			err:      nil,
+			expected: nil,
+		},
+		{
+			err: fmt.Errorf("secret"),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusInternalServerError,
+				Code:          "internal",
+				PublicMessage: "Internal Server Error",
+			},
+		},
+		{
+			err: errorz.Errorf("secret"),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusInternalServerError,
+				Code:          "internal",
+				PublicMessage: "Internal Server Error",
+			},
+		},
+		{
+			err: errorz.Wrap(fmt.Errorf("secret"), errorz.WithHTTPStatus(http.StatusNotFound)),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusNotFound,
+				Code:          "not_found",
+				PublicMessage: "Not Found",
+			},
+		},
+		{
+			err: errorz.Wrap(fmt.Errorf("secret"), errorz.WithHTTPStatus(999)),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusInternalServerError,
+				Code:          "internal",
+				PublicMessage: "Internal Server Error",
+			},
+		},
+		{
+			err: errorz.Wrap(
+				fmt.Errorf("secret"),
+				errorz.WithHTTPStatus(http.StatusBadRequest),
+				errorz.WithCode("invalid-user"),
+				errorz.WithPublicMessage("Invalid user.")),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusBadRequest,
+				Code:          "invalid-user",
+				PublicMessage: "Invalid user.",
+			},
+		},
+		{
+			err: errorz.Wrap(
+				&classifiedError{httpStatus: http.StatusConflict, code: "inner-code", publicMessage: "Inner."},
+				errorz.WithCode("outer-code")),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusConflict,
+				Code:          "outer-code",
+				PublicMessage: "Inner.",
+			},
+		},
+		{
+			err: errorz.Wrap(
+				fmt.Errorf("x: %w", errorz.Wrap(fmt.Errorf("secret"), errorz.WithHTTPStatus(http.StatusForbidden))),
+				errorz.WithPublicMessage("Outer.")),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusForbidden,
+				Code:          "forbidden",
+				PublicMessage: "Outer.",
+			},
+		},
+		{
+			err: errorz.Wrap(
+				fmt.Errorf("secret"),
+				&classifiedError{httpStatus: http.StatusServiceUnavailable}),
+			expected: &errorz.Classification{
+				HTTPStatus:    http.StatusServiceUnavailable,
+				Code:          "internal",
+				PublicMessage: "Service Unavailable",
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("%03v", i+1), func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(errorz.Classify(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package errorz
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
@@ -17,26 +19,127 @@ var (
 	}
 )
 
+type dump struct {
+	Message string
+	Debug   []error
+	Frames  []string
+}
+
+// newDump returns a value to be dumped for the wrapped error. Metadata and trace are omitted when empty.
+func newDump(e *wrappedError) any {
+	md := Redact(GetAllMetadata(e))
+	trace := newTraceDump(e)
+
+	switch {
+	case len(md) == 0 && len(trace) == 0:
+		return dump{
+			Message: e.Error(),
+			Debug:   Redact(e.getErrs()),
+			Frames:  e.stack.getFrames().ToSummaries(),
+		}
+	case len(trace) == 0:
+		type dump struct {
+			Message  string
+			Debug    []error
+			Metadata map[string]any
+			Frames   []string
+		}
+
+		return dump{
+			Message:  e.Error(),
+			Debug:    Redact(e.getErrs()),
+			Metadata: md,
+			Frames:   e.stack.getFrames().ToSummaries(),
+		}
+	case len(md) == 0:
+		type dump struct {
+			Message string
+			Debug   []error
+			Trace   []string
+			Frames  []string
+		}
+
+		return dump{
+			Message: e.Error(),
+			Debug:   Redact(e.getErrs()),
+			Trace:   trace,
+			Frames:  e.stack.getFrames().ToSummaries(),
+		}
+	default:
+		type dump struct {
+			Message  string
+			Debug    []error
+			Metadata map[string]any
+			Trace    []string
+			Frames   []string
+		}
+
+		return dump{
+			Message:  e.Error(),
+			Debug:    Redact(e.getErrs()),
+			Metadata: md,
+			Trace:    trace,
+			Frames:   e.stack.getFrames().ToSummaries(),
+		}
+	}
+}
+
+func newTraceDump(e *wrappedError) []string {
+	var trace []string
+
+	for _, te := range GetTrace(e) {
+		msgs := make([]string, 0, len(te.Errors))
+
+		for _, err := range te.Errors {
+			msgs = append(msgs, strconv.Quote(err.Error()))
+		}
+
+		if len(msgs) > 0 {
+			trace = append(trace, fmt.Sprintf("%v: %v", te.Frame.Summary, strings.Join(msgs, ", ")))
+		} else {
+			trace = append(trace, te.Frame.Summary)
+		}
+	}
+
+	return trace
+}
+
 // SDump converts the error to a string representation for debug purposes.
+// If the error was wrapped while wrap tracing was enabled, the representation includes the trace.
+// Sensitive values in the errors and metadata are redacted (see Redact).
 func SDump(err error) string {
 	if err == nil {
 		return "<nil>"
 	}
 
 	if e, ok := err.(*wrappedError); ok {
-		type dump struct {
+		return strings.TrimSuffix(spewConfig.Sdump(newDump(e)), "\n")
+	}
+
+	if e, ok := err.(*MultiError); ok {
+		type entryDump struct {
+			Key   string
+			Error any
+		}
+
+		type multiErrorDump struct {
 			Message string
-			Debug   []error
-			Frames  []string
+			Errors  []entryDump
+		}
+
+		d := multiErrorDump{
+			Message: e.Error(),
+			Errors:  make([]entryDump, 0),
+		}
+
+		for _, entry := range e.GetEntries() {
+			d.Errors = append(d.Errors, entryDump{
+				Key:   entry.Key,
+				Error: newDump(entry.Err.(*wrappedError)),
+			})
 		}
 
-		return strings.TrimSuffix(
-			spewConfig.Sdump(dump{
-				Message: e.Error(),
-				Debug:   e.errs,
-				Frames:  e.frames.ToSummaries(),
-			}),
-			"\n")
+		return strings.TrimSuffix(spewConfig.Sdump(d), "\n")
 	}
 
 	type dump struct {
@@ -47,7 +150,7 @@ func SDump(err error) string {
 	return strings.TrimSuffix(
 		spewConfig.Sdump(dump{
 			Message: err.Error(),
-			Debug:   []error{err},
+			Debug:   Redact([]error{err}),
 		}),
 		"\n")
 }
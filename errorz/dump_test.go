@@ -15,4 +15,12 @@ func TestDump(t *testing.T) {
 	g.Expect(errorz.SDump(nil)).To(Equal("<nil>"))
 	g.Expect(errorz.SDump(fmt.Errorf("e"))).ToNot(BeEmpty())
 	g.Expect(errorz.SDump(errorz.Errorf("e"))).ToNot(BeEmpty())
+	g.Expect(errorz.SDump(errorz.Errorf("e"))).To(HavePrefix("(errorz.dump)"))
+	g.Expect(errorz.SDump(errorz.Errorf("e"))).ToNot(ContainSubstring("Metadata:"))
+	g.Expect(errorz.SDump(errorz.Errorf("e"))).ToNot(ContainSubstring("Trace:"))
+
+	dump := errorz.SDump(errorz.Wrap(fmt.Errorf("e"), errorz.WithMetadata("k", "v")))
+	g.Expect(dump).To(HavePrefix("(errorz.dump)"))
+	g.Expect(dump).To(ContainSubstring(`Metadata: (map[string]interface {}) (len=1) {`))
+	g.Expect(dump).ToNot(ContainSubstring("Trace:"))
 }
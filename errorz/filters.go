@@ -0,0 +1,154 @@
+package errorz
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FrameFilter describes a function which returns true if the given frame should be filtered out of stack traces.
+type FrameFilter func(frame *Frame) bool
+
+// FrameFilterMode describes how filtered frames are handled.
+type FrameFilterMode int
+
+// Known frame filter modes.
+const (
+	// FrameFilterModeRemove removes filtered frames.
+	FrameFilterModeRemove FrameFilterMode = iota
+
+	// FrameFilterModeCollapse collapses consecutive filtered frames into a single "... N frames elided" frame.
+	FrameFilterModeCollapse
+)
+
+var (
+	frameFiltersM    = &sync.Mutex{}
+	frameFilterKeys  []string
+	frameFilters     = map[string]FrameFilter{}
+	frameFilterMode  = FrameFilterModeRemove
+	stdlibFrameCache = &sync.Map{}
+)
+
+// RegisterFrameFilter registers (or replaces) a frame filter under the given key.
+// Filters are evaluated when frames are symbolized, i.e. the first time frames are requested from a wrapped error.
+// Frames from this package and from "runtime.gopanic" are always removed, regardless of the registered filters.
+func RegisterFrameFilter(key string, filter FrameFilter) {
+	frameFiltersM.Lock()
+	defer frameFiltersM.Unlock()
+
+	if _, ok := frameFilters[key]; !ok {
+		frameFilterKeys = append(frameFilterKeys, key)
+	}
+
+	frameFilters[key] = filter
+}
+
+// UnregisterFrameFilter unregisters the frame filter with the given key (if any).
+func UnregisterFrameFilter(key string) {
+	frameFiltersM.Lock()
+	defer frameFiltersM.Unlock()
+
+	delete(frameFilters, key)
+	frameFilterKeys = slices.DeleteFunc(frameFilterKeys, func(k string) bool { return k == key })
+}
+
+// SetFrameFilterMode sets how filtered frames are handled.
+func SetFrameFilterMode(mode FrameFilterMode) {
+	frameFiltersM.Lock()
+	defer frameFiltersM.Unlock()
+
+	frameFilterMode = mode
+}
+
+// RestoreDefaultFrameFilters unregisters all frame filters and restores the default frame filter mode.
+func RestoreDefaultFrameFilters() {
+	frameFiltersM.Lock()
+	defer frameFiltersM.Unlock()
+
+	frameFilterKeys = nil
+	frameFilters = map[string]FrameFilter{}
+	frameFilterMode = FrameFilterModeRemove
+}
+
+// NewPackagePrefixFrameFilter returns a FrameFilter that filters frames whose full package has any of the given prefixes.
+func NewPackagePrefixFrameFilter(prefixes ...string) FrameFilter {
+	return func(frame *Frame) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(frame.Package, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// NewFunctionRegexpFrameFilter returns a FrameFilter that filters frames whose full location matches the given regexp.
+func NewFunctionRegexpFrameFilter(re *regexp.Regexp) FrameFilter {
+	return func(frame *Frame) bool {
+		return frame.Location != "" && re.MatchString(frame.Location)
+	}
+}
+
+// StdlibFrameFilter is a FrameFilter that filters frames from the standard library, i.e. frames whose full package
+// does not contain a dot in its first path element (with the exception of "main").
+func StdlibFrameFilter(frame *Frame) bool {
+	if frame.Package == "" || frame.Package == "main" {
+		return false
+	}
+
+	if v, ok := stdlibFrameCache.Load(frame.Package); ok {
+		return v.(bool)
+	}
+
+	first, _, _ := strings.Cut(frame.Package, "/")
+	isStdlib := !strings.Contains(first, ".")
+	stdlibFrameCache.Store(frame.Package, isStdlib)
+	return isStdlib
+}
+
+func newElidedFrame(count int) *Frame {
+	return &Frame{
+		Summary: fmt.Sprintf("... %v frames elided", count),
+		Elided:  count,
+	}
+}
+
+func filterFrames(frames Frames) Frames {
+	frameFiltersM.Lock()
+	filters := make([]FrameFilter, 0, len(frameFilterKeys))
+	for _, key := range frameFilterKeys {
+		filters = append(filters, frameFilters[key])
+	}
+	mode := frameFilterMode
+	frameFiltersM.Unlock()
+
+	if len(filters) == 0 {
+		return frames
+	}
+
+	filtered := make(Frames, 0, len(frames))
+	elided := 0
+
+	for _, frame := range frames {
+		if slices.ContainsFunc(filters, func(filter FrameFilter) bool { return filter(frame) }) {
+			elided++
+			continue
+		}
+
+		if elided > 0 && mode == FrameFilterModeCollapse {
+			filtered = append(filtered, newElidedFrame(elided))
+		}
+
+		elided = 0
+		filtered = append(filtered, frame)
+	}
+
+	if elided > 0 && mode == FrameFilterModeCollapse {
+		filtered = append(filtered, newElidedFrame(elided))
+	}
+
+	return filtered
+}
@@ -0,0 +1,75 @@
+package errorz_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func TestFrameFilters(t *testing.T) {
+	g := NewWithT(t)
+	defer errorz.RestoreDefaultFrameFilters()
+
+	g.Expect(errorz.GetFrames(nil).ToSummaries()).To(HaveExactElements(
+		HavePrefix("errorz_test.TestFrameFilters "),
+		HavePrefix("testing.tRunner "),
+		HavePrefix("runtime.goexit ")))
+
+	errorz.RegisterFrameFilter("testing", errorz.NewPackagePrefixFrameFilter("testing"))
+	g.Expect(errorz.GetFrames(nil).ToSummaries()).To(HaveExactElements(
+		HavePrefix("errorz_test.TestFrameFilters "),
+		HavePrefix("runtime.goexit ")))
+
+	errorz.SetFrameFilterMode(errorz.FrameFilterModeCollapse)
+	g.Expect(errorz.GetFrames(nil).ToSummaries()).To(HaveExactElements(
+		HavePrefix("errorz_test.TestFrameFilters "),
+		"... 1 frames elided",
+		HavePrefix("runtime.goexit ")))
+
+	errorz.RegisterFrameFilter("goexit", errorz.NewFunctionRegexpFrameFilter(regexp.MustCompile(`^runtime\.goexit$`)))
+	frames := errorz.GetFrames(nil)
+	g.Expect(frames.ToSummaries()).To(HaveExactElements(
+		HavePrefix("errorz_test.TestFrameFilters "),
+		"... 2 frames elided"))
+	g.Expect(frames[1]).To(Equal(&errorz.Frame{Summary: "... 2 frames elided", Elided: 2}))
+
+	errorz.UnregisterFrameFilter("testing")
+	errorz.UnregisterFrameFilter("goexit")
+	errorz.UnregisterFrameFilter("unknown")
+	errorz.RegisterFrameFilter("stdlib", errorz.StdlibFrameFilter)
+	errorz.RegisterFrameFilter("stdlib", errorz.StdlibFrameFilter)
+	g.Expect(errorz.GetFrames(nil).ToSummaries()).To(HaveExactElements(
+		HavePrefix("errorz_test.TestFrameFilters "),
+		"... 2 frames elided"))
+
+	errorz.RegisterFrameFilter("all", func(*errorz.Frame) bool { return true })
+	g.Expect(errorz.GetFrames(nil).ToSummaries()).To(HaveExactElements("... 3 frames elided"))
+
+	errorz.SetFrameFilterMode(errorz.FrameFilterModeRemove)
+	g.Expect(errorz.GetFrames(nil)).To(BeEmpty())
+}
+
+func TestStdlibFrameFilter(t *testing.T) {
+	type testCase struct {
+		frame    *errorz.Frame
+		expected bool
+	}
+
+	for i, tc := range []testCase{
+		{frame: &errorz.Frame{}, expected: false},
+		{frame: &errorz.Frame{Package: "main"}, expected: false},
+		{frame: &errorz.Frame{Package: "testing"}, expected: true},
+		{frame: &errorz.Frame{Package: "net/http"}, expected: true},
+		{frame: &errorz.Frame{Package: "net/http"}, expected: true},
+		{frame: &errorz.Frame{Package: "github.com/ibrt/golang-lib/errorz"}, expected: false},
+	} {
+		t.Run(fmt.Sprintf("%03v", i+1), func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(errorz.StdlibFrameFilter(tc.frame)).To(Equal(tc.expected))
+		})
+	}
+}
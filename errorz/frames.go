@@ -5,6 +5,7 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"sync"
 )
 
 // Frame describes a frame.
@@ -18,6 +19,7 @@ type Frame struct {
 	FileAndLine   string `json:"fileAndLine,omitempty"`
 	File          string `json:"file,omitempty"`
 	Line          int    `json:"line,omitempty"`
+	Elided        int    `json:"elided,omitempty"`
 }
 
 // NewFrame initializes a new frame.
@@ -91,31 +93,88 @@ func (f Frames) ToSummaries() []string {
 }
 
 // GetFrames returns the frames from the error, or the current frames the error is not wrapped or is nil.
+// Frames of wrapped errors are captured as program counters when wrapping, and only symbolized on first access.
 func GetFrames(err error) Frames {
 	if e, ok := err.(*wrappedError); ok {
-		return e.frames
+		return e.stack.getFrames()
 	}
 
-	callers := make([]uintptr, 1024)
-	callers = callers[:runtime.Callers(1, callers[:])]
-	callersFrames := runtime.CallersFrames(callers)
+	return newStack().getFrames()
+}
+
+const (
+	initialStackDepth = 64
+	maxStackDepth     = 1024
+)
 
-	frames := make([]*Frame, 0, len(callers))
+// stack lazily symbolizes a set of captured program counters into frames.
+type stack struct {
+	once   *sync.Once
+	pcs    []uintptr
+	frames Frames
+}
+
+// newStack captures the program counters of the current goroutine, without symbolizing them.
+func newStack() *stack {
+	pcs := make([]uintptr, initialStackDepth)
+
+	for {
+		n := runtime.Callers(2, pcs)
+
+		if n < len(pcs) || len(pcs) >= maxStackDepth {
+			pcs = pcs[:n]
+			break
+		}
+
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+
+	return &stack{
+		once: &sync.Once{},
+		pcs:  pcs,
+	}
+}
+
+// newSymbolizedStack initializes a stack from already symbolized frames.
+func newSymbolizedStack(frames Frames) *stack {
+	s := &stack{
+		once:   &sync.Once{},
+		frames: frames,
+	}
+
+	s.once.Do(func() {})
+	return s
+}
+
+func (s *stack) getFrames() Frames {
+	s.once.Do(func() {
+		s.frames = symbolizeFrames(s.pcs)
+	})
+
+	return s.frames
+}
+
+func symbolizeFrames(pcs []uintptr) Frames {
+	frames := make([]*Frame, 0, len(pcs))
+
+	if len(pcs) == 0 {
+		return frames
+	}
+
+	callersFrames := runtime.CallersFrames(pcs)
 
 	for {
 		callerFrame, more := callersFrames.Next()
 		frame := NewFrame(callerFrame.Function, callerFrame.File, callerFrame.Line)
 
-		if frame.ShortPackage == "errorz" || frame.ShortLocation == "runtime.gopanic" {
-			continue
+		if frame.ShortPackage != "errorz" && frame.ShortLocation != "runtime.gopanic" {
+			frames = append(frames, frame)
 		}
 
-		frames = append(frames, frame)
-
 		if !more {
 			break
 		}
 	}
 
-	return frames
+	return filterFrames(frames)
 }
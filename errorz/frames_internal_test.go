@@ -0,0 +1,23 @@
+package errorz
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// BenchmarkWrap_Eager is the baseline for BenchmarkWrap: it captures the program counters and symbolizes them into
+// frames at wrap time, as Wrap did before symbolization was made lazy.
+func BenchmarkWrap_Eager(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		pcs := make([]uintptr, maxStackDepth)
+		pcs = pcs[:runtime.Callers(1, pcs)]
+
+		_ = &wrappedError{
+			m:     &sync.Mutex{},
+			errs:  []error{fmt.Errorf("e")},
+			stack: newSymbolizedStack(symbolizeFrames(pcs)),
+		}
+	}
+}
@@ -164,3 +164,34 @@ func TestGetFrames(t *testing.T) {
 	g.Expect(len(frames)).To(BeNumerically(">", 0))
 	g.Expect(frames[0].ShortLocation).To(Equal("errorz_test.TestGetFrames"))
 }
+
+// BenchmarkWrap measures wrapping, which only captures program counters: frames are symbolized lazily.
+// See BenchmarkWrap_Eager for the baseline.
+func BenchmarkWrap(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = errorz.Wrap(fmt.Errorf("e"))
+	}
+}
+
+func BenchmarkWrap_SDump(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = errorz.SDump(errorz.Wrap(fmt.Errorf("e")))
+	}
+}
+
+func BenchmarkGetFrames(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = errorz.GetFrames(nil)
+	}
+}
+
+func BenchmarkWrap_CopyOnWrap(b *testing.B) {
+	errorz.SetCopyOnWrapEnabled(true)
+	defer errorz.SetCopyOnWrapEnabled(false)
+
+	err := errorz.Errorf("e")
+
+	for i := 0; i < b.N; i++ {
+		_ = errorz.Wrap(err, fmt.Errorf("o"))
+	}
+}
@@ -0,0 +1,139 @@
+package errorz
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	_ GroupOption = GroupOptionFunc(nil)
+)
+
+// GroupOption describes a Group option.
+type GroupOption interface {
+	Apply(*Group)
+}
+
+// GroupOptionFunc describes a Group option.
+type GroupOptionFunc func(*Group)
+
+// Apply implements the GroupOption interface.
+func (f GroupOptionFunc) Apply(g *Group) {
+	f(g)
+}
+
+// GroupLimit returns a Group option that limits the number of goroutines running concurrently.
+// A zero or negative value (the default) means no limit.
+func GroupLimit(limit int) GroupOptionFunc {
+	return func(g *Group) {
+		if limit > 0 {
+			g.sem = make(chan struct{}, limit)
+		} else {
+			g.sem = nil
+		}
+	}
+}
+
+// GroupCollectAll returns a Group option that makes Wait return all the errors aggregated in a *MultiError, instead of
+// only the first one. In this mode the Group context is not canceled when a goroutine fails.
+func GroupCollectAll() GroupOptionFunc {
+	return func(g *Group) {
+		g.collectAll = true
+	}
+}
+
+// Group runs functions in goroutines and collects their errors, similarly to "golang.org/x/sync/errgroup".
+// Panics in the goroutines are recovered and converted to errors using WrapRecover, so that they include the frames of
+// the panicking goroutine. By default, the Group context is canceled as soon as a goroutine fails, and Wait returns
+// the first error.
+type Group struct {
+	ctx        context.Context
+	cancel     context.CancelCauseFunc
+	wg         *sync.WaitGroup
+	sem        chan struct{}
+	collectAll bool
+	m          *sync.Mutex
+	firstErr   error
+	multiErr   *MultiError
+}
+
+// NewGroup initializes a new Group, returning it alongside a derived context which is canceled when a goroutine
+// fails (unless GroupCollectAll is used) or when Wait returns, whichever happens first.
+func NewGroup(ctx context.Context, options ...GroupOption) (*Group, context.Context) {
+	g := &Group{
+		wg:       &sync.WaitGroup{},
+		m:        &sync.Mutex{},
+		multiErr: NewMultiError(),
+	}
+
+	g.ctx, g.cancel = context.WithCancelCause(ctx)
+
+	for _, option := range options {
+		option.Apply(g)
+	}
+
+	return g, g.ctx
+}
+
+// Go runs the given function in a new goroutine, passing it the Group context.
+// If a concurrency limit is set, it blocks until the goroutine can be started.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+
+		if err := g.run(f); err != nil {
+			g.handleError(err)
+		}
+	}()
+}
+
+// Wait blocks until all the goroutines have completed, then returns the first error, or all the errors aggregated
+// in a *MultiError if GroupCollectAll is used. It returns nil if no goroutine failed.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+
+	if g.collectAll {
+		return g.multiErr.ErrorOrNil()
+	}
+
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	return g.firstErr
+}
+
+func (g *Group) run(f func(ctx context.Context) error) (err error) {
+	defer func() {
+		if rErr := MaybeWrapRecover(recover()); rErr != nil {
+			err = rErr
+		}
+	}()
+
+	return MaybeWrap(f(g.ctx))
+}
+
+func (g *Group) handleError(err error) {
+	if g.collectAll {
+		g.multiErr.Add(err)
+		return
+	}
+
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	if g.firstErr == nil {
+		g.firstErr = err
+		g.cancel(err)
+	}
+}
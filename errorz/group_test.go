@@ -0,0 +1,137 @@
+package errorz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func TestGroup_Success(t *testing.T) {
+	g := NewWithT(t)
+
+	grp, ctx := errorz.NewGroup(context.Background())
+	count := &atomic.Int32{}
+
+	for i := 0; i < 10; i++ {
+		grp.Go(func(ctx context.Context) error {
+			count.Add(1)
+			return nil
+		})
+	}
+
+	g.Expect(grp.Wait()).To(Succeed())
+	g.Expect(count.Load()).To(BeEquivalentTo(10))
+	g.Expect(ctx.Err()).To(MatchError(context.Canceled))
+}
+
+func TestGroup_FirstError(t *testing.T) {
+	g := NewWithT(t)
+
+	e1 := stringError("e1")
+	grp, ctx := errorz.NewGroup(context.Background())
+
+	grp.Go(func(ctx context.Context) error {
+		return e1
+	})
+
+	grp.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return fmt.Errorf("e2")
+	})
+
+	err := grp.Wait()
+	g.Expect(errors.Is(err, e1)).To(BeTrue())
+	g.Expect(err.Error()).To(Equal("e1"))
+	g.Expect(context.Cause(ctx)).To(Equal(err))
+}
+
+func TestGroup_Panic(t *testing.T) {
+	g := NewWithT(t)
+
+	grp, _ := errorz.NewGroup(context.Background())
+
+	grp.Go(func(ctx context.Context) error {
+		panicInGroup()
+		return nil
+	})
+
+	err := grp.Wait()
+	g.Expect(err).To(MatchError("panicked"))
+	g.Expect(errorz.GetFrames(err)[0].ShortLocation).To(Equal("errorz_test.panicInGroup"))
+}
+
+func panicInGroup() {
+	panic("panicked")
+}
+
+func TestGroup_CollectAll(t *testing.T) {
+	g := NewWithT(t)
+
+	grp, ctx := errorz.NewGroup(context.Background(), errorz.GroupCollectAll())
+
+	grp.Go(func(ctx context.Context) error {
+		return stringError("e1")
+	})
+
+	grp.Go(func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		g.Expect(ctx.Err()).To(Succeed())
+		panic(stringError("e2"))
+	})
+
+	grp.Go(func(ctx context.Context) error {
+		return nil
+	})
+
+	err := grp.Wait()
+	g.Expect(err).ToNot(BeNil())
+	g.Expect(errors.Is(err, stringError("e1"))).To(BeTrue())
+	g.Expect(errors.Is(err, stringError("e2"))).To(BeTrue())
+
+	mErr, ok := errorz.As[*errorz.MultiError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(mErr.Len()).To(Equal(2))
+	g.Expect(ctx.Err()).To(MatchError(context.Canceled))
+
+	grp, _ = errorz.NewGroup(context.Background(), errorz.GroupCollectAll())
+	g.Expect(grp.Wait()).To(Succeed())
+}
+
+func TestGroup_Limit(t *testing.T) {
+	g := NewWithT(t)
+
+	grp, _ := errorz.NewGroup(context.Background(), errorz.GroupLimit(2))
+	running := &atomic.Int32{}
+	maxRunning := &atomic.Int32{}
+
+	for i := 0; i < 10; i++ {
+		grp.Go(func(ctx context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+
+	g.Expect(grp.Wait()).To(Succeed())
+	g.Expect(maxRunning.Load()).To(BeNumerically("<=", 2))
+
+	grp, _ = errorz.NewGroup(context.Background(), errorz.GroupLimit(2), errorz.GroupLimit(0))
+	grp.Go(func(ctx context.Context) error { return nil })
+	g.Expect(grp.Wait()).To(Succeed())
+}
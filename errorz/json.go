@@ -0,0 +1,216 @@
+package errorz
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+var (
+	_ json.Marshaler = (*wrappedError)(nil)
+	_ error          = (*DecodedError)(nil)
+	_ UnwrapMulti    = (*DecodedError)(nil)
+)
+
+type jsonError struct {
+	Message  string                     `json:"message"`
+	Errors   []*jsonLayer               `json:"errors,omitempty"`
+	Metadata map[string]json.RawMessage `json:"metadata,omitempty"`
+	Frames   Frames                     `json:"frames,omitempty"`
+}
+
+type jsonLayer struct {
+	Type    string          `json:"type,omitempty"`
+	Message string          `json:"message"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Wrapped *jsonError      `json:"wrapped,omitempty"`
+	Causes  []*jsonLayer    `json:"causes,omitempty"`
+}
+
+// DecodedError describes an error reconstructed by DecodeJSON, for which the original type is not available.
+type DecodedError struct {
+	typeName string
+	message  string
+	causes   []error
+}
+
+// GetType returns the name of the original error type.
+func (e *DecodedError) GetType() string {
+	return e.typeName
+}
+
+// Error implements the error interface.
+func (e *DecodedError) Error() string {
+	return e.message
+}
+
+// Unwrap implements the UnwrapMulti interface.
+func (e *DecodedError) Unwrap() []error {
+	if e == nil {
+		return nil
+	}
+
+	return e.causes
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (e *wrappedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONError(e))
+}
+
+// EncodeJSON encodes the error to JSON. Wrapped errors are encoded with their stack of errors, metadata, and frames,
+// other errors are encoded as a single layer without frames. Use DecodeJSON to reconstruct an equivalent error.
+// Sensitive metadata and recovered values are redacted (see Redact).
+func EncodeJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+
+	if e, ok := err.(*wrappedError); ok {
+		return e.MarshalJSON()
+	}
+
+	buf, mErr := json.Marshal(&jsonError{
+		Message: err.Error(),
+		Errors:  []*jsonLayer{newJSONLayer(err)},
+	})
+	return buf, MaybeWrap(mErr)
+}
+
+// DecodeJSON decodes an error previously encoded using EncodeJSON. Errors in the stack whose type is not known are
+// reconstructed as *DecodedError, values recovered from panics are reconstructed with their JSON-decoded value.
+// It returns a nil error if the JSON value is null.
+func DecodeJSON(buf []byte) (error, error) {
+	var jErr *jsonError
+
+	if err := json.Unmarshal(buf, &jErr); err != nil {
+		return nil, Wrap(err)
+	}
+
+	if jErr == nil {
+		return nil, nil
+	}
+
+	return jErr.toError(), nil
+}
+
+func newJSONError(e *wrappedError) *jsonError {
+	errs := e.getErrs()
+	jErr := &jsonError{
+		Message:  e.Error(),
+		Errors:   make([]*jsonLayer, 0, len(errs)),
+		Metadata: nil,
+		Frames:   e.stack.getFrames(),
+	}
+
+	for _, err := range errs {
+		jErr.Errors = append(jErr.Errors, newJSONLayer(err))
+	}
+
+	if md := Redact(e.getMetadata()); len(md) > 0 {
+		jErr.Metadata = make(map[string]json.RawMessage, len(md))
+
+		for k, v := range md {
+			jErr.Metadata[k] = marshalJSONValue(v)
+		}
+	}
+
+	return jErr
+}
+
+func newJSONLayer(err error) *jsonLayer {
+	l := &jsonLayer{
+		Type:    fmt.Sprintf("%T", err),
+		Message: err.Error(),
+	}
+
+	switch e := err.(type) {
+	case *DecodedError:
+		l.Type = e.typeName
+
+		for _, cause := range e.causes {
+			l.Causes = append(l.Causes, newJSONLayer(cause))
+		}
+	case *wrappedError:
+		l.Wrapped = newJSONError(e)
+	case *valueError:
+		l.Value = marshalJSONValue(Redact(e.Value))
+	case UnwrapMulti:
+		for _, cause := range e.Unwrap() {
+			if cause != nil {
+				l.Causes = append(l.Causes, newJSONLayer(cause))
+			}
+		}
+	case UnwrapSingle:
+		if cause := e.Unwrap(); cause != nil {
+			l.Causes = append(l.Causes, newJSONLayer(cause))
+		}
+	}
+
+	return l
+}
+
+func (j *jsonError) toError() error {
+	e := &wrappedError{
+		m:        &sync.Mutex{},
+		errs:     make([]error, 0, len(j.Errors)),
+		stack:    newSymbolizedStack(j.Frames),
+		metadata: nil,
+	}
+
+	for _, l := range j.Errors {
+		e.errs = append(e.errs, l.toError())
+	}
+
+	if len(e.errs) == 0 {
+		e.errs = append(e.errs, &DecodedError{message: j.Message})
+	}
+
+	if len(j.Metadata) > 0 {
+		e.metadata = make(map[string]any, len(j.Metadata))
+
+		for k, v := range j.Metadata {
+			e.metadata[k] = unmarshalJSONValue(v)
+		}
+	}
+
+	return e
+}
+
+func (l *jsonLayer) toError() error {
+	if l.Wrapped != nil {
+		return l.Wrapped.toError()
+	}
+
+	if l.Value != nil {
+		return &valueError{
+			Value: unmarshalJSONValue(l.Value),
+		}
+	}
+
+	e := &DecodedError{
+		typeName: l.Type,
+		message:  l.Message,
+	}
+
+	for _, cause := range l.Causes {
+		e.causes = append(e.causes, cause.toError())
+	}
+
+	return e
+}
+
+func marshalJSONValue(v any) json.RawMessage {
+	if buf, err := json.Marshal(v); err == nil {
+		return buf
+	}
+
+	buf, _ := json.Marshal(fmt.Sprintf("%v", v))
+	return buf
+}
+
+func unmarshalJSONValue(buf json.RawMessage) any {
+	var v any
+	_ = json.Unmarshal(buf, &v)
+	return v
+}
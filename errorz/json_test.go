@@ -0,0 +1,106 @@
+package errorz_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func TestJSON(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.Wrap(
+		fmt.Errorf("x: %w", &structError{k: "e"}),
+		stringError("o1"),
+		errorz.WithMetadata("k1", "v1"),
+		errorz.WithMetadata("k2", func() {}))
+
+	buf, encErr := errorz.EncodeJSON(err)
+	g.Expect(encErr).To(Succeed())
+
+	jsonBuf, encErr := json.Marshal(err)
+	g.Expect(encErr).To(Succeed())
+	g.Expect(jsonBuf).To(MatchJSON(buf))
+
+	decErr, err2 := errorz.DecodeJSON(buf)
+	g.Expect(err2).To(Succeed())
+	g.Expect(decErr).ToNot(BeNil())
+	g.Expect(decErr.Error()).To(Equal("o1: x: e"))
+	g.Expect(errorz.GetFrames(decErr)).To(Equal(errorz.GetFrames(err)))
+	g.Expect(errorz.GetAllMetadata(decErr)).To(HaveKeyWithValue("k1", "v1"))
+	g.Expect(errorz.GetAllMetadata(decErr)).To(HaveKeyWithValue("k2", HavePrefix("0x")))
+
+	dErr, ok := errorz.As[*errorz.DecodedError](decErr)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(dErr.GetType()).To(Equal("*fmt.wrapError"))
+	g.Expect(dErr.Error()).To(Equal("x: e"))
+	g.Expect(dErr.Unwrap()).To(HaveLen(1))
+	g.Expect(dErr.Unwrap()[0].(*errorz.DecodedError).GetType()).To(Equal("*errorz_test.structError"))
+
+	reBuf, encErr := errorz.EncodeJSON(decErr)
+	g.Expect(encErr).To(Succeed())
+	g.Expect(reBuf).To(MatchJSON(buf))
+}
+
+func TestJSON_Recover(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.WrapRecover(map[string]any{"k": "v"}, stringError("o1"))
+
+	buf, encErr := errorz.EncodeJSON(err)
+	g.Expect(encErr).To(Succeed())
+
+	decErr, err2 := errorz.DecodeJSON(buf)
+	g.Expect(err2).To(Succeed())
+	g.Expect(decErr.Error()).To(Equal("o1: map[k:v]"))
+
+	dErr, ok := errorz.As[*errorz.DecodedError](decErr)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(dErr.GetType()).To(Equal("errorz_test.stringError"))
+	g.Expect(dErr.Error()).To(Equal("o1"))
+	g.Expect(errorz.GetFrames(decErr)).To(Equal(errorz.GetFrames(err)))
+}
+
+func TestJSON_NotWrapped(t *testing.T) {
+	g := NewWithT(t)
+
+	buf, err := errorz.EncodeJSON(errors.Join(fmt.Errorf("e1"), fmt.Errorf("e2")))
+	g.Expect(err).To(Succeed())
+
+	decErr, err := errorz.DecodeJSON(buf)
+	g.Expect(err).To(Succeed())
+	g.Expect(decErr.Error()).To(Equal("e1\ne2"))
+	g.Expect(errorz.GetFrames(decErr)).To(BeEmpty())
+
+	dErr, ok := errorz.As[*errorz.DecodedError](decErr)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(dErr.GetType()).To(Equal("*errors.joinError"))
+	g.Expect(dErr.Unwrap()).To(HaveLen(2))
+}
+
+func TestJSON_Edge(t *testing.T) {
+	g := NewWithT(t)
+
+	buf, err := errorz.EncodeJSON(nil)
+	g.Expect(err).To(Succeed())
+	g.Expect(string(buf)).To(Equal("null"))
+
+	decErr, err := errorz.DecodeJSON(buf)
+	g.Expect(err).To(Succeed())
+	g.Expect(decErr).To(BeNil())
+
+	decErr, err = errorz.DecodeJSON([]byte(`{"message":"m"}`))
+	g.Expect(err).To(Succeed())
+	g.Expect(decErr).To(MatchError("m"))
+
+	decErr, err = errorz.DecodeJSON([]byte(`{`))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(decErr).To(BeNil())
+
+	g.Expect((*errorz.DecodedError)(nil).Unwrap()).To(BeNil())
+}
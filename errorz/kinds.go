@@ -0,0 +1,122 @@
+package errorz
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	_ error        = (*Kind)(nil)
+	_ error        = (*kindError)(nil)
+	_ IsHelper     = (*kindError)(nil)
+	_ CodeProvider = (*kindError)(nil)
+)
+
+var (
+	kindsM = &sync.Mutex{}
+	kinds  = map[string]*Kind{}
+)
+
+// Kind describes a sentinel error kind, with a stable code and a message template.
+// Errors instantiated from a Kind match it using "errors.Is", and provide its code for classification.
+type Kind struct {
+	code     string
+	template string
+}
+
+// NewKind declares and registers a new Kind, typically assigned to a package-level variable.
+// The template is formatted using "fmt.Sprintf" with the arguments passed to New.
+// It panics if another Kind with the same code is already registered.
+func NewKind(code, template string) *Kind {
+	Assertf(code != "", "code is empty")
+
+	kindsM.Lock()
+	defer kindsM.Unlock()
+
+	_, ok := kinds[code]
+	Assertf(!ok, "duplicate kind code: %v", code)
+
+	k := &Kind{
+		code:     code,
+		template: template,
+	}
+
+	kinds[code] = k
+	return k
+}
+
+// GetKinds returns all registered kinds, sorted by code. Useful to generate error catalogs.
+func GetKinds() []*Kind {
+	kindsM.Lock()
+	defer kindsM.Unlock()
+
+	ks := make([]*Kind, 0, len(kinds))
+
+	for _, k := range kinds {
+		ks = append(ks, k)
+	}
+
+	sort.Slice(ks, func(i, j int) bool {
+		return ks[i].code < ks[j].code
+	})
+
+	return ks
+}
+
+// GetKind returns the Kind of the first error in the chain that was instantiated from a Kind.
+func GetKind(err error) (*Kind, bool) {
+	if kErr, ok := As[*kindError](err); ok {
+		return kErr.kind, true
+	}
+
+	return nil, false
+}
+
+// GetCode returns the code.
+func (k *Kind) GetCode() string {
+	return k.code
+}
+
+// GetTemplate returns the message template.
+func (k *Kind) GetTemplate() string {
+	return k.template
+}
+
+// Error implements the error interface.
+func (k *Kind) Error() string {
+	return k.code
+}
+
+// New instantiates a wrapped error of this Kind, formatting the template with the given arguments.
+func (k *Kind) New(a ...any) error {
+	return Wrap(&kindError{
+		kind:    k,
+		message: fmt.Sprintf(k.template, a...),
+	})
+}
+
+// MustNew is like New but panics with the wrapped error instead of returning it.
+func (k *Kind) MustNew(a ...any) {
+	panic(k.New(a...))
+}
+
+type kindError struct {
+	kind    *Kind
+	message string
+}
+
+// Error implements the error interface.
+func (e *kindError) Error() string {
+	return e.message
+}
+
+// Is provides interoperability with "errors.Is".
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// GetCode implements the CodeProvider interface.
+func (e *kindError) GetCode() string {
+	return e.kind.code
+}
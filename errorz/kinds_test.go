@@ -0,0 +1,70 @@
+package errorz_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+var (
+	errKindTestNotFound = errorz.NewKind("test-not-found", "item %v not found")
+	errKindTestInvalid  = errorz.NewKind("test-invalid", "invalid item")
+)
+
+func TestKind(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(errKindTestNotFound.GetCode()).To(Equal("test-not-found"))
+	g.Expect(errKindTestNotFound.GetTemplate()).To(Equal("item %v not found"))
+	g.Expect(errKindTestNotFound.Error()).To(Equal("test-not-found"))
+
+	err := errKindTestNotFound.New("x")
+	g.Expect(err).To(MatchError("item x not found"))
+	g.Expect(errors.Is(err, errKindTestNotFound)).To(BeTrue())
+	g.Expect(errors.Is(err, errKindTestInvalid)).To(BeFalse())
+	g.Expect(errorz.GetFrames(err)[0].ShortLocation).To(Equal("errorz_test.TestKind"))
+
+	err = errorz.Wrap(fmt.Errorf("x: %w", err), fmt.Errorf("outer"))
+	g.Expect(err).To(MatchError("outer: x: item x not found"))
+	g.Expect(errors.Is(err, errKindTestNotFound)).To(BeTrue())
+
+	k, ok := errorz.GetKind(err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(k).To(BeIdenticalTo(errKindTestNotFound))
+
+	k, ok = errorz.GetKind(fmt.Errorf("e"))
+	g.Expect(ok).To(BeFalse())
+	g.Expect(k).To(BeNil())
+
+	g.Expect(errorz.Classify(errorz.Wrap(errKindTestInvalid.New(), errorz.WithHTTPStatus(http.StatusBadRequest)))).
+		To(Equal(&errorz.Classification{
+			HTTPStatus:    http.StatusBadRequest,
+			Code:          "test-invalid",
+			PublicMessage: "Bad Request",
+		}))
+
+	g.Expect(func() { errKindTestInvalid.MustNew() }).To(PanicWith(MatchError("invalid item")))
+}
+
+func TestNewKind(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(func() { errorz.NewKind("", "") }).To(PanicWith(MatchError("code is empty")))
+	g.Expect(func() { errorz.NewKind("test-invalid", "") }).To(PanicWith(MatchError("duplicate kind code: test-invalid")))
+}
+
+func TestGetKinds(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(errorz.GetKinds()).To(ContainElements(errKindTestInvalid, errKindTestNotFound))
+
+	kinds := errorz.GetKinds()
+	for i := 1; i < len(kinds); i++ {
+		g.Expect(kinds[i-1].GetCode() < kinds[i].GetCode()).To(BeTrue())
+	}
+}
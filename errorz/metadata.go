@@ -0,0 +1,72 @@
+package errorz
+
+// WithMetadata returns a WrapOption that attaches the given key/value pair to the wrapped error.
+func WithMetadata(k string, v any) WrapOption {
+	return wrapOptionFunc(func(e *wrappedError) {
+		if e.metadata == nil {
+			e.metadata = make(map[string]any)
+		}
+
+		e.metadata[k] = v
+	})
+}
+
+// WithMetadataMap returns a WrapOption that attaches all the given key/value pairs to the wrapped error.
+func WithMetadataMap(md map[string]any) WrapOption {
+	return wrapOptionFunc(func(e *wrappedError) {
+		if len(md) == 0 {
+			return
+		}
+
+		if e.metadata == nil {
+			e.metadata = make(map[string]any, len(md))
+		}
+
+		for k, v := range md {
+			e.metadata[k] = v
+		}
+	})
+}
+
+// GetMetadata returns the metadata value for the given key, looking it up on all the wrapped errors in the chain.
+// If the same key is attached more than once, the outermost value wins. It returns false if the key is not found or
+// if its value is not of type T.
+func GetMetadata[T any](err error, k string) (T, bool) {
+	var (
+		v     any
+		found bool
+	)
+
+	walk(err, func(err error) bool {
+		if e, ok := err.(*wrappedError); ok {
+			v, found = e.getMetadata()[k]
+		}
+		return !found
+	})
+
+	t, ok := v.(T)
+	return t, found && ok
+}
+
+// GetAllMetadata returns all the metadata attached to the wrapped errors in the chain, merged together.
+// If the same key is attached more than once, the outermost value wins. It returns nil if no metadata is found.
+func GetAllMetadata(err error) map[string]any {
+	var md map[string]any
+
+	walk(err, func(err error) bool {
+		if e, ok := err.(*wrappedError); ok {
+			for k, v := range e.getMetadata() {
+				if md == nil {
+					md = make(map[string]any)
+				}
+
+				if _, ok := md[k]; !ok {
+					md[k] = v
+				}
+			}
+		}
+		return true
+	})
+
+	return md
+}
@@ -0,0 +1,65 @@
+package errorz_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func TestMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.Wrap(fmt.Errorf("e"), errorz.WithMetadata("k1", "v1"), fmt.Errorf("o1"))
+	g.Expect(err.Error()).To(Equal("o1: e"))
+	g.Expect(err.(errorz.UnwrapMulti).Unwrap()).To(HaveLen(1))
+
+	{
+		v, ok := errorz.GetMetadata[string](err, "k1")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(v).To(Equal("v1"))
+	}
+	{
+		_, ok := errorz.GetMetadata[int](err, "k1")
+		g.Expect(ok).To(BeFalse())
+	}
+	{
+		_, ok := errorz.GetMetadata[string](err, "k2")
+		g.Expect(ok).To(BeFalse())
+	}
+
+	err = errorz.Wrap(err, errorz.WithMetadataMap(map[string]any{"k1": "v1-override", "k2": 2}), nil)
+	g.Expect(err.Error()).To(Equal("o1: e"))
+	g.Expect(errorz.GetAllMetadata(err)).To(Equal(map[string]any{"k1": "v1-override", "k2": 2}))
+
+	{
+		v, ok := errorz.GetMetadata[int](err, "k2")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(v).To(Equal(2))
+	}
+
+	g.Expect(errorz.WithMetadata("k", "v").Error()).To(Equal("wrap option"))
+	g.Expect(errorz.GetAllMetadata(nil)).To(BeNil())
+	g.Expect(errorz.GetAllMetadata(fmt.Errorf("e"))).To(BeNil())
+	g.Expect(errorz.GetAllMetadata(errorz.Errorf("e"))).To(BeNil())
+	g.Expect(errorz.GetAllMetadata(errorz.Wrap(fmt.Errorf("e"), errorz.WithMetadataMap(nil)))).To(BeNil())
+}
+
+func TestMetadata_ReWrap(t *testing.T) {
+	g := NewWithT(t)
+
+	inner := errorz.Wrap(fmt.Errorf("e"), errorz.WithMetadata("k1", "inner"), errorz.WithMetadata("k2", "inner"))
+	outer := errorz.Wrap(fmt.Errorf("x: %w", inner), errorz.WithMetadata("k1", "outer"))
+	g.Expect(errorz.GetAllMetadata(outer)).To(Equal(map[string]any{"k1": "outer", "k2": "inner"}))
+
+	err := errorz.Wrap(fmt.Errorf("e"), errorz.Errorf("o1"), errorz.Wrap(fmt.Errorf("o2"), errorz.WithMetadata("k1", "o2")))
+	g.Expect(err.Error()).To(Equal("o2: o1: e"))
+
+	v, ok := errorz.GetMetadata[string](err, "k1")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal("o2"))
+
+	g.Expect(errorz.SDump(outer)).To(ContainSubstring(`"outer"`))
+}
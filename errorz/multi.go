@@ -0,0 +1,132 @@
+package errorz
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	_ error       = (*MultiError)(nil)
+	_ UnwrapMulti = (*MultiError)(nil)
+)
+
+// MultiErrorEntry describes an error collected by a MultiError, optionally associated with a key.
+type MultiErrorEntry struct {
+	Key string
+	Err error
+}
+
+// MultiError collects multiple independent errors, for example from the validation of a batch of items or from
+// concurrent operations. It is safe for concurrent use. It interoperates with "errors.Is" and "errors.As".
+type MultiError struct {
+	m       *sync.Mutex
+	entries []*MultiErrorEntry
+}
+
+// NewMultiError initializes a new, empty MultiError.
+func NewMultiError() *MultiError {
+	return &MultiError{
+		m:       &sync.Mutex{},
+		entries: make([]*MultiErrorEntry, 0),
+	}
+}
+
+// Add wraps and adds the given error, capturing its frames if not already wrapped. It does nothing if err is nil.
+func (e *MultiError) Add(err error) {
+	e.AddWithKey("", err)
+}
+
+// AddWithKey is like Add, but associates the error with the given key (e.g. the index or ID of an item in a batch).
+func (e *MultiError) AddWithKey(key string, err error) {
+	if err == nil {
+		return
+	}
+
+	err = Wrap(err)
+
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.entries = append(e.entries, &MultiErrorEntry{
+		Key: key,
+		Err: err,
+	})
+}
+
+// Len returns the number of collected errors.
+func (e *MultiError) Len() int {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return len(e.entries)
+}
+
+// GetEntries returns the collected errors, with their keys, in the order they were added.
+func (e *MultiError) GetEntries() []*MultiErrorEntry {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	entries := make([]*MultiErrorEntry, 0, len(e.entries))
+
+	for _, entry := range e.entries {
+		entries = append(entries, &MultiErrorEntry{
+			Key: entry.Key,
+			Err: entry.Err,
+		})
+	}
+
+	return entries
+}
+
+// ErrorOrNil returns the MultiError if at least one error was collected, nil otherwise.
+func (e *MultiError) ErrorOrNil() error {
+	if e == nil || e.Len() == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Error implements the error interface.
+func (e *MultiError) Error() string {
+	entries := e.GetEntries()
+
+	if len(entries) == 1 {
+		return entries[0].String()
+	}
+
+	w := &strings.Builder{}
+	_, _ = fmt.Fprintf(w, "%v errors occurred:", len(entries))
+
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(w, "\n  * %v", strings.ReplaceAll(entry.String(), "\n", "\n    "))
+	}
+
+	return w.String()
+}
+
+// Unwrap implements the UnwrapMulti interface.
+func (e *MultiError) Unwrap() []error {
+	if e == nil {
+		return nil
+	}
+
+	entries := e.GetEntries()
+	errs := make([]error, 0, len(entries))
+
+	for _, entry := range entries {
+		errs = append(errs, entry.Err)
+	}
+
+	return errs
+}
+
+// String implements the fmt.Stringer interface.
+func (e *MultiErrorEntry) String() string {
+	if e.Key != "" {
+		return fmt.Sprintf("%v: %v", e.Key, e.Err.Error())
+	}
+
+	return e.Err.Error()
+}
@@ -0,0 +1,75 @@
+package errorz_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func TestMultiError(t *testing.T) {
+	g := NewWithT(t)
+
+	e1 := &structError{k: "e1"}
+	e2 := stringError("e2")
+
+	mErr := errorz.NewMultiError()
+	g.Expect(mErr.Len()).To(Equal(0))
+	g.Expect(mErr.ErrorOrNil()).To(BeNil())
+	g.Expect(mErr.GetEntries()).To(BeEmpty())
+	g.Expect(mErr.Unwrap()).To(BeEmpty())
+	g.Expect((*errorz.MultiError)(nil).ErrorOrNil()).To(BeNil())
+	g.Expect((*errorz.MultiError)(nil).Unwrap()).To(BeNil())
+
+	mErr.Add(nil)
+	mErr.Add(e1)
+	g.Expect(mErr.Len()).To(Equal(1))
+	g.Expect(mErr.ErrorOrNil()).To(MatchError("e1"))
+
+	mErr.AddWithKey("k2", errorz.Wrap(e2, fmt.Errorf("line 1\nline 2")))
+	g.Expect(mErr.Len()).To(Equal(2))
+	g.Expect(mErr.Error()).To(Equal("2 errors occurred:\n  * e1\n  * k2: line 1\n    line 2: e2"))
+
+	entries := mErr.GetEntries()
+	g.Expect(entries).To(HaveLen(2))
+	g.Expect(entries[0].Key).To(BeEmpty())
+	g.Expect(entries[0].Err).To(MatchError("e1"))
+	g.Expect(entries[1].Key).To(Equal("k2"))
+	g.Expect(entries[1].String()).To(Equal("k2: line 1\nline 2: e2"))
+	g.Expect(errorz.GetFrames(entries[0].Err)[0].ShortLocation).To(Equal("errorz_test.TestMultiError"))
+
+	g.Expect(errors.Is(mErr, e1)).To(BeTrue())
+	g.Expect(errors.Is(mErr, e2)).To(BeTrue())
+	g.Expect(errors.Is(mErr, stringError("e3"))).To(BeFalse())
+
+	sErr, ok := errorz.As[*structError](mErr)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(sErr).To(Equal(e1))
+
+	g.Expect(errorz.SDump(mErr)).To(ContainSubstring(`Key: (string) (len=2) "k2"`))
+}
+
+func TestMultiError_Concurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	mErr := errorz.NewMultiError()
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			mErr.AddWithKey(strconv.Itoa(i), fmt.Errorf("e%v", i))
+		}()
+	}
+
+	wg.Wait()
+	g.Expect(mErr.Len()).To(Equal(100))
+	g.Expect(mErr.Unwrap()).To(HaveLen(100))
+}
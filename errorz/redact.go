@@ -0,0 +1,234 @@
+package errorz
+
+import (
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// RedactedValue is the placeholder which replaces redacted string values.
+const RedactedValue = "[REDACTED]"
+
+// RedactTag is the struct tag used to mark fields as redacted, i.e. `errorz:"redact"`.
+const RedactTag = "errorz"
+
+const (
+	defaultRedactedFieldPatternKey = "default"
+)
+
+var (
+	// DefaultRedactedFieldPattern matches the names of fields which commonly hold sensitive values.
+	DefaultRedactedFieldPattern = regexp.MustCompile(
+		`(?i)(passw(or)?d|passphrase|secret|token|api[-_]?key|authorization|credential|private[-_]?key|cookie)`)
+)
+
+var (
+	redactorType = reflect.TypeFor[Redactor]()
+)
+
+// Redactor describes a value which provides its own redacted copy, used by Redact instead of the default logic.
+// The returned value must be of the same type as the receiver.
+type Redactor interface {
+	Redact() any
+}
+
+var (
+	redactedFieldPatternsM   = &sync.Mutex{}
+	redactedFieldPatternKeys = []string{defaultRedactedFieldPatternKey}
+	redactedFieldPatterns    = map[string]*regexp.Regexp{defaultRedactedFieldPatternKey: DefaultRedactedFieldPattern}
+)
+
+// RegisterRedactedFieldPattern registers (or replaces) a pattern under the given key. Struct fields and string map keys
+// whose name matches any of the registered patterns are redacted. DefaultRedactedFieldPattern is registered by default.
+func RegisterRedactedFieldPattern(key string, re *regexp.Regexp) {
+	redactedFieldPatternsM.Lock()
+	defer redactedFieldPatternsM.Unlock()
+
+	if _, ok := redactedFieldPatterns[key]; !ok {
+		redactedFieldPatternKeys = append(redactedFieldPatternKeys, key)
+	}
+
+	redactedFieldPatterns[key] = re
+}
+
+// UnregisterRedactedFieldPattern unregisters the pattern with the given key (if any).
+func UnregisterRedactedFieldPattern(key string) {
+	redactedFieldPatternsM.Lock()
+	defer redactedFieldPatternsM.Unlock()
+
+	delete(redactedFieldPatterns, key)
+	redactedFieldPatternKeys = slices.DeleteFunc(redactedFieldPatternKeys, func(k string) bool { return k == key })
+}
+
+// RestoreDefaultRedactedFieldPatterns unregisters all patterns and registers DefaultRedactedFieldPattern.
+func RestoreDefaultRedactedFieldPatterns() {
+	redactedFieldPatternsM.Lock()
+	defer redactedFieldPatternsM.Unlock()
+
+	redactedFieldPatternKeys = []string{defaultRedactedFieldPatternKey}
+	redactedFieldPatterns = map[string]*regexp.Regexp{defaultRedactedFieldPatternKey: DefaultRedactedFieldPattern}
+}
+
+// IsRedactedField returns true if the given field name (or map key) matches any of the registered patterns.
+func IsRedactedField(name string) bool {
+	redactedFieldPatternsM.Lock()
+	defer redactedFieldPatternsM.Unlock()
+
+	for _, key := range redactedFieldPatternKeys {
+		if redactedFieldPatterns[key].MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact returns a deep copy of the given value in which sensitive values are redacted. A struct field is redacted if
+// it is tagged with `errorz:"redact"` or if its name matches a registered pattern (see RegisterRedactedFieldPattern).
+// A map entry is redacted if its key is a string matching a registered pattern. Redacted strings are replaced by
+// RedactedValue, other redacted values are replaced by their zero value. Unexported fields are redacted as well.
+func Redact[T any](v T) T {
+	rv := reflect.ValueOf(&v).Elem()
+	rv.Set(redactValue(rv, map[redactKey]reflect.Value{}))
+	return v
+}
+
+// redactKey identifies an already redacted pointer, so that cyclic and shared values are only copied once.
+type redactKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+func redactValue(v reflect.Value, seen map[redactKey]reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Interface && v.Type().Implements(redactorType) && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		if r := reflect.ValueOf(v.Interface().(Redactor).Redact()); r.IsValid() && r.Type().AssignableTo(v.Type()) {
+			return r
+		}
+
+		return reflect.Zero(v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		if p, ok := seen[redactKey{t: v.Type(), p: v.Pointer()}]; ok {
+			return p
+		}
+
+		if e, ok := v.Interface().(*wrappedError); ok {
+			return reflect.ValueOf(redactWrappedError(e, seen))
+		}
+
+		p := reflect.New(v.Type().Elem())
+		seen[redactKey{t: v.Type(), p: v.Pointer()}] = p
+		p.Elem().Set(redactValue(v.Elem(), seen))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		i := reflect.New(v.Type()).Elem()
+		i.Set(redactValue(v.Elem(), seen))
+		return i
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+
+		for i := 0; i < s.NumField(); i++ {
+			sf := v.Type().Field(i)
+			f := s.Field(i)
+			f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+
+			if sf.Tag.Get(RedactTag) == "redact" || IsRedactedField(sf.Name) {
+				f.Set(redactedValue(f.Type()))
+			} else {
+				f.Set(redactValue(f, seen))
+			}
+		}
+
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+		for it := v.MapRange(); it.Next(); {
+			if it.Key().Kind() == reflect.String && IsRedactedField(it.Key().String()) {
+				m.SetMapIndex(it.Key(), redactedValue(v.Type().Elem()))
+			} else {
+				m.SetMapIndex(it.Key(), redactValue(it.Value(), seen))
+			}
+		}
+
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(redactValue(v.Index(i), seen))
+		}
+
+		return s
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(redactValue(v.Index(i), seen))
+		}
+
+		return a
+	default:
+		return v
+	}
+}
+
+func redactedValue(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(RedactedValue).Convert(t)
+	}
+
+	if t.Kind() == reflect.Interface && reflect.TypeOf(RedactedValue).Implements(t) {
+		v := reflect.New(t).Elem()
+		v.Set(reflect.ValueOf(RedactedValue))
+		return v
+	}
+
+	return reflect.Zero(t)
+}
+
+func redactWrappedError(e *wrappedError, seen map[redactKey]reflect.Value) *wrappedError {
+	e.m.Lock()
+	errs := slices.Clone(e.errs)
+	metadata := maps.Clone(e.metadata)
+	trace := slices.Clone(e.trace)
+	e.m.Unlock()
+
+	r := &wrappedError{
+		m:     &sync.Mutex{},
+		errs:  make([]error, 0, len(errs)),
+		stack: e.stack,
+		trace: trace,
+	}
+
+	seen[redactKey{t: reflect.TypeOf(e), p: reflect.ValueOf(e).Pointer()}] = reflect.ValueOf(r)
+	r.metadata = Redact(metadata)
+
+	for _, err := range errs {
+		r.errs = append(r.errs, redactValue(reflect.ValueOf(&err).Elem(), seen).Interface().(error))
+	}
+
+	return r
+}
@@ -0,0 +1,142 @@
+package errorz_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+type redactCredentials struct {
+	User     string
+	Password string
+	Note     string `errorz:"redact"`
+	Count    int    `errorz:"redact"`
+	password string
+	token    any
+	next     *redactCredentials
+}
+
+type redactError struct {
+	creds  *redactCredentials
+	params map[string]any
+}
+
+// Error implements the error interface.
+func (e *redactError) Error() string {
+	return "redact error"
+}
+
+type redactorError struct {
+	secret string
+}
+
+// Error implements the error interface.
+func (e *redactorError) Error() string {
+	return "redactor error"
+}
+
+// Redact implements the errorz.Redactor interface.
+func (e *redactorError) Redact() any {
+	return &redactorError{secret: "custom"}
+}
+
+func TestIsRedactedField(t *testing.T) {
+	defer errorz.RestoreDefaultRedactedFieldPatterns()
+	g := NewWithT(t)
+
+	for _, name := range []string{"password", "Passwd", "DB_PASSWORD", "secret", "ClientSecret", "GITHUB_TOKEN", "apiKey", "API-KEY", "Authorization", "Credentials", "private_key", "Cookie"} {
+		g.Expect(errorz.IsRedactedField(name)).To(BeTrue(), name)
+	}
+
+	for _, name := range []string{"user", "PWD", "PATH", "key", "name"} {
+		g.Expect(errorz.IsRedactedField(name)).To(BeFalse(), name)
+	}
+
+	errorz.RegisterRedactedFieldPattern("ssn", regexp.MustCompile(`(?i)^ssn$`))
+	g.Expect(errorz.IsRedactedField("SSN")).To(BeTrue())
+	g.Expect(errorz.IsRedactedField("password")).To(BeTrue())
+
+	errorz.UnregisterRedactedFieldPattern("default")
+	g.Expect(errorz.IsRedactedField("SSN")).To(BeTrue())
+	g.Expect(errorz.IsRedactedField("password")).To(BeFalse())
+
+	errorz.RestoreDefaultRedactedFieldPatterns()
+	g.Expect(errorz.IsRedactedField("SSN")).To(BeFalse())
+	g.Expect(errorz.IsRedactedField("password")).To(BeTrue())
+}
+
+func TestRedact(t *testing.T) {
+	g := NewWithT(t)
+
+	creds := &redactCredentials{
+		User:     "u",
+		Password: "p",
+		Note:     "n",
+		Count:    1,
+		password: "p",
+		token:    "t",
+	}
+	creds.next = creds
+
+	r := errorz.Redact(creds)
+	g.Expect(r).ToNot(BeIdenticalTo(creds))
+	g.Expect(r.User).To(Equal("u"))
+	g.Expect(r.Password).To(Equal(errorz.RedactedValue))
+	g.Expect(r.Note).To(Equal(errorz.RedactedValue))
+	g.Expect(r.Count).To(Equal(0))
+	g.Expect(r.next).To(BeIdenticalTo(r))
+	g.Expect(fmt.Sprintf("%v", r)).To(ContainSubstring("[REDACTED] [REDACTED]"))
+	g.Expect(creds.Password).To(Equal("p"))
+	g.Expect(creds.password).To(Equal("p"))
+
+	g.Expect(errorz.Redact(map[string]any{"k": "v", "token": 1, "password": "p", "nested": map[string]string{"secret": "s"}})).
+		To(Equal(map[string]any{"k": "v", "token": "[REDACTED]", "password": "[REDACTED]", "nested": map[string]string{"secret": "[REDACTED]"}}))
+	g.Expect(errorz.Redact([]any{map[string]int{"token": 1}, [1]map[string]int{{"token": 1}}})).
+		To(Equal([]any{map[string]int{"token": 0}, [1]map[string]int{{"token": 0}}}))
+	g.Expect(errorz.Redact[any](nil)).To(BeNil())
+	g.Expect(errorz.Redact[*redactCredentials](nil)).To(BeNil())
+	g.Expect(errorz.Redact[map[string]any](nil)).To(BeNil())
+	g.Expect(errorz.Redact[[]string](nil)).To(BeNil())
+	g.Expect(errorz.Redact("s")).To(Equal("s"))
+	g.Expect(errorz.Redact[error](&redactorError{secret: "s"})).To(Equal(&redactorError{secret: "custom"}))
+}
+
+func TestRedact_Output(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.Wrap(
+		&redactError{
+			creds:  &redactCredentials{User: "u", Password: "p4ss", password: "p4ss", token: "t0ken"},
+			params: map[string]any{"apiKey": "k3y", "name": "n"},
+		},
+		errorz.Wrap(&redactorError{secret: "s3cret"}),
+		errorz.WithMetadata("sessionToken", "t0ken"),
+		errorz.WithMetadata("user", "u"))
+
+	dump := errorz.SDump(err)
+	g.Expect(dump).ToNot(ContainSubstring("p4ss"))
+	g.Expect(dump).ToNot(ContainSubstring("t0ken"))
+	g.Expect(dump).ToNot(ContainSubstring("k3y"))
+	g.Expect(dump).ToNot(ContainSubstring("s3cret"))
+	g.Expect(dump).To(ContainSubstring(`(string) (len=4) "name": (string) (len=1) "n"`))
+	g.Expect(dump).To(ContainSubstring(`(string) (len=4) "user": (string) (len=1) "u"`))
+	g.Expect(dump).To(ContainSubstring(`(string) (len=6) "custom"`))
+	g.Expect(errorz.SDump(&redactError{creds: &redactCredentials{Password: "p4ss"}})).ToNot(ContainSubstring("p4ss"))
+
+	token, _ := errorz.GetMetadata[string](err, "sessionToken")
+	g.Expect(token).To(Equal("t0ken"))
+
+	buf, jErr := json.Marshal(err)
+	g.Expect(jErr).To(Succeed())
+	g.Expect(string(buf)).ToNot(ContainSubstring("t0ken"))
+	g.Expect(string(buf)).To(ContainSubstring(`"sessionToken":"[REDACTED]"`))
+
+	buf, jErr = errorz.EncodeJSON(errorz.WrapRecover(map[string]string{"password": "p4ss"}))
+	g.Expect(jErr).To(Succeed())
+	g.Expect(string(buf)).ToNot(ContainSubstring("p4ss"))
+}
@@ -0,0 +1,294 @@
+package errorz
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+var (
+	_ RetrierOption   = RetrierOptionFunc(nil)
+	_ BackoffPolicy   = BackoffPolicyFunc(nil)
+	_ error           = (*RetryError)(nil)
+	_ UnwrapSingle    = (*RetryError)(nil)
+	_ RetryClassifier = DefaultRetryClassifier
+)
+
+// MetadataKeyRetryable is the metadata key used by the WithRetryable option.
+const MetadataKeyRetryable = "errorz.retryable"
+
+// Default Retrier values.
+const (
+	DefaultRetryMaxAttempts = 5
+)
+
+// RetryableProvider describes an error which declares whether the failed operation can be retried.
+type RetryableProvider interface {
+	IsRetryable() bool
+}
+
+// WithRetryable returns a WrapOption that marks the wrapped error as retryable or permanent.
+func WithRetryable(retryable bool) WrapOption {
+	return WithMetadata(MetadataKeyRetryable, retryable)
+}
+
+// Permanent wraps the error marking it as not retryable. It returns nil if the error is nil.
+func Permanent(err error) error {
+	return MaybeWrap(err, WithRetryable(false))
+}
+
+// RetryClassifier decides whether a failed operation can be retried based on its error.
+type RetryClassifier func(err error) bool
+
+// DefaultRetryClassifier walks the error chain, outermost error first, looking for the WithRetryable option or an
+// error implementing RetryableProvider. If none is found, it falls back to the error classification: errors with an
+// HTTP status of 408, 429, or 5xx (including unclassified errors) are retryable, all the others are permanent.
+func DefaultRetryClassifier(err error) bool {
+	var (
+		retryable bool
+		found     bool
+	)
+
+	walk(err, func(err error) bool {
+		if e, ok := err.(*wrappedError); ok {
+			retryable, found = e.getMetadata()[MetadataKeyRetryable].(bool)
+		}
+
+		if p, ok := err.(RetryableProvider); ok && !found {
+			retryable, found = p.IsRetryable(), true
+		}
+
+		return !found
+	})
+
+	if found {
+		return retryable
+	}
+
+	switch httpStatus := Classify(err).HTTPStatus; {
+	case httpStatus == http.StatusRequestTimeout, httpStatus == http.StatusTooManyRequests:
+		return true
+	default:
+		return httpStatus >= http.StatusInternalServerError
+	}
+}
+
+// BackoffPolicy describes a policy that computes the delay before retrying a failed attempt.
+type BackoffPolicy interface {
+	GetDelay(attempt int) time.Duration
+}
+
+// BackoffPolicyFunc describes a policy that computes the delay before retrying a failed attempt.
+// The attempt number starts at 1.
+type BackoffPolicyFunc func(attempt int) time.Duration
+
+// GetDelay implements the BackoffPolicy interface.
+func (f BackoffPolicyFunc) GetDelay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// ConstantBackoff returns a BackoffPolicy that always waits the given delay.
+func ConstantBackoff(delay time.Duration) BackoffPolicyFunc {
+	return func(_ int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff returns a BackoffPolicy that waits the initial delay after the first attempt, then multiplies it
+// by the given factor after each subsequent attempt, up to the max delay (if positive).
+func ExponentialBackoff(initialDelay, maxDelay time.Duration, factor float64) BackoffPolicyFunc {
+	return func(attempt int) time.Duration {
+		delay := float64(initialDelay) * math.Pow(factor, float64(max(attempt-1, 0)))
+
+		if maxDelay > 0 && delay > float64(maxDelay) {
+			return maxDelay
+		}
+
+		return time.Duration(delay)
+	}
+}
+
+// JitterBackoff returns a BackoffPolicy that randomizes the delays of the given policy by up to ±factor (e.g. 0.2
+// means ±20%), so that many clients failing at the same time don't retry in lockstep.
+func JitterBackoff(policy BackoffPolicy, factor float64) BackoffPolicyFunc {
+	return func(attempt int) time.Duration {
+		delay := float64(policy.GetDelay(attempt))
+		return time.Duration(max(delay+delay*factor*(2*rand.Float64()-1), 0))
+	}
+}
+
+// DefaultBackoffPolicy is the BackoffPolicy used by default: exponential from 100ms to 10s with ±20% jitter.
+var DefaultBackoffPolicy BackoffPolicy = JitterBackoff(ExponentialBackoff(100*time.Millisecond, 10*time.Second, 2), 0.2)
+
+// RetryAttempt describes a failed attempt.
+type RetryAttempt struct {
+	Attempt   int
+	Err       error
+	StartTime time.Time
+	Duration  time.Duration
+}
+
+// RetryError is the error returned by a Retrier when the operation fails permanently or the retries are exhausted.
+// It records every failed attempt, and unwraps to the error returned by the last one.
+type RetryError struct {
+	attempts []*RetryAttempt
+}
+
+// Error implements the error interface.
+func (e *RetryError) Error() string {
+	if len(e.attempts) == 0 {
+		return "retry failed before the first attempt"
+	}
+
+	return fmt.Sprintf("retry failed after %v attempt(s): %v", len(e.attempts), e.attempts[len(e.attempts)-1].Err.Error())
+}
+
+// Unwrap implements the UnwrapSingle interface.
+func (e *RetryError) Unwrap() error {
+	if len(e.attempts) == 0 {
+		return nil
+	}
+
+	return e.attempts[len(e.attempts)-1].Err
+}
+
+// GetAttempts returns the failed attempts.
+func (e *RetryError) GetAttempts() []*RetryAttempt {
+	return e.attempts
+}
+
+// RetrierOption describes a Retrier option.
+type RetrierOption interface {
+	Apply(*Retrier)
+}
+
+// RetrierOptionFunc describes a Retrier option.
+type RetrierOptionFunc func(*Retrier)
+
+// Apply implements the RetrierOption interface.
+func (f RetrierOptionFunc) Apply(r *Retrier) {
+	f(r)
+}
+
+// RetrierMaxAttempts returns a Retrier option that sets the maximum number of attempts.
+// A zero or negative value means no limit. Defaults to DefaultRetryMaxAttempts.
+func RetrierMaxAttempts(maxAttempts int) RetrierOptionFunc {
+	return func(r *Retrier) {
+		r.maxAttempts = maxAttempts
+	}
+}
+
+// RetrierMaxElapsed returns a Retrier option that stops retrying if the next attempt would start after the given
+// duration has elapsed since the first one. A zero or negative value (the default) means no limit.
+func RetrierMaxElapsed(maxElapsed time.Duration) RetrierOptionFunc {
+	return func(r *Retrier) {
+		r.maxElapsed = maxElapsed
+	}
+}
+
+// RetrierBackoff returns a Retrier option that sets the BackoffPolicy. Defaults to DefaultBackoffPolicy.
+func RetrierBackoff(backoff BackoffPolicy) RetrierOptionFunc {
+	return func(r *Retrier) {
+		r.backoff = backoff
+	}
+}
+
+// RetrierClassifier returns a Retrier option that sets the RetryClassifier. Defaults to DefaultRetryClassifier.
+func RetrierClassifier(classifier RetryClassifier) RetrierOptionFunc {
+	return func(r *Retrier) {
+		r.classifier = classifier
+	}
+}
+
+// Retrier runs an operation until it succeeds, it fails with a permanent error, or the retries are exhausted.
+type Retrier struct {
+	maxAttempts int
+	maxElapsed  time.Duration
+	backoff     BackoffPolicy
+	classifier  RetryClassifier
+}
+
+// NewRetrier initializes a new Retrier.
+func NewRetrier(options ...RetrierOption) *Retrier {
+	r := &Retrier{
+		maxAttempts: DefaultRetryMaxAttempts,
+		maxElapsed:  0,
+		backoff:     DefaultBackoffPolicy,
+		classifier:  DefaultRetryClassifier,
+	}
+
+	for _, option := range options {
+		option.Apply(r)
+	}
+
+	return r
+}
+
+// Run runs the operation, retrying it as needed. Panics are not recovered. On failure, it returns a wrapped
+// *RetryError which records all the attempts. If the context is done while waiting to retry, its error is added as
+// an outer error.
+func (r *Retrier) Run(ctx context.Context, f func(ctx context.Context) error) error {
+	rErr := &RetryError{
+		attempts: make([]*RetryAttempt, 0),
+	}
+
+	startTime := time.Now()
+
+	if err := ctx.Err(); err != nil {
+		return Wrap(rErr, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptStartTime := time.Now()
+		err := f(ctx)
+
+		if err == nil {
+			return nil
+		}
+
+		rErr.attempts = append(rErr.attempts, &RetryAttempt{
+			Attempt:   attempt,
+			Err:       err,
+			StartTime: attemptStartTime,
+			Duration:  time.Since(attemptStartTime),
+		})
+
+		if !r.classifier(err) || (r.maxAttempts > 0 && attempt >= r.maxAttempts) {
+			return Wrap(rErr)
+		}
+
+		delay := r.backoff.GetDelay(attempt)
+
+		if r.maxElapsed > 0 && time.Since(startTime)+delay > r.maxElapsed {
+			return Wrap(rErr)
+		}
+
+		if err := r.wait(ctx, delay); err != nil {
+			return Wrap(rErr, err)
+		}
+	}
+}
+
+func (r *Retrier) wait(ctx context.Context, delay time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Retry is a shorthand for NewRetrier(options...).Run(ctx, f).
+func Retry(ctx context.Context, f func(ctx context.Context) error, options ...RetrierOption) error {
+	return NewRetrier(options...).Run(ctx, f)
+}
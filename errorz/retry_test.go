@@ -0,0 +1,249 @@
+package errorz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+type retryableError struct {
+	retryable bool
+}
+
+// Error implements the error interface.
+func (e *retryableError) Error() string {
+	return fmt.Sprintf("retryable: %v", e.retryable)
+}
+
+// IsRetryable implements the errorz.RetryableProvider interface.
+func (e *retryableError) IsRetryable() bool {
+	return e.retryable
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	testCases := []struct {
+		err       error
+		retryable bool
+	}{
+		{
+			err:       fmt.Errorf("e"),
+			retryable: true,
+		},
+		{
+			err:       errorz.Errorf("e"),
+			retryable: true,
+		},
+		{
+			err:       errorz.Permanent(errorz.Errorf("e")),
+			retryable: false,
+		},
+		{
+			err:       errorz.Wrap(errorz.Permanent(errorz.Errorf("e")), errorz.WithRetryable(true)),
+			retryable: true,
+		},
+		{
+			err:       errorz.Wrap(&retryableError{retryable: false}),
+			retryable: false,
+		},
+		{
+			err:       errorz.Wrap(&retryableError{retryable: false}, errorz.WithRetryable(true)),
+			retryable: true,
+		},
+		{
+			err:       fmt.Errorf("o: %w", &retryableError{retryable: true}),
+			retryable: true,
+		},
+		{
+			err:       errorz.Wrap(fmt.Errorf("e"), errorz.WithHTTPStatus(http.StatusBadRequest)),
+			retryable: false,
+		},
+		{
+			err:       errorz.Wrap(fmt.Errorf("e"), errorz.WithHTTPStatus(http.StatusTooManyRequests)),
+			retryable: true,
+		},
+		{
+			err:       errorz.Wrap(fmt.Errorf("e"), errorz.WithHTTPStatus(http.StatusRequestTimeout)),
+			retryable: true,
+		},
+		{
+			err:       errorz.Wrap(fmt.Errorf("e"), errorz.WithHTTPStatus(http.StatusBadGateway)),
+			retryable: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%03v", i+1), func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(errorz.DefaultRetryClassifier(tc.err)).To(Equal(tc.retryable))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect(errorz.Permanent(nil)).To(Succeed())
+}
+
+func TestBackoffPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	p := errorz.ConstantBackoff(time.Second)
+	g.Expect(p.GetDelay(1)).To(Equal(time.Second))
+	g.Expect(p.GetDelay(10)).To(Equal(time.Second))
+
+	p = errorz.ExponentialBackoff(time.Second, 5*time.Second, 2)
+	g.Expect(p.GetDelay(1)).To(Equal(time.Second))
+	g.Expect(p.GetDelay(2)).To(Equal(2 * time.Second))
+	g.Expect(p.GetDelay(3)).To(Equal(4 * time.Second))
+	g.Expect(p.GetDelay(4)).To(Equal(5 * time.Second))
+
+	p = errorz.ExponentialBackoff(time.Second, 0, 3)
+	g.Expect(p.GetDelay(4)).To(Equal(27 * time.Second))
+
+	p = errorz.JitterBackoff(errorz.ConstantBackoff(time.Second), 0.5)
+	for i := 0; i < 100; i++ {
+		g.Expect(p.GetDelay(i)).To(BeNumerically("~", time.Second, 500*time.Millisecond))
+	}
+
+	p = errorz.JitterBackoff(errorz.ConstantBackoff(time.Second), 2)
+	for i := 0; i < 100; i++ {
+		g.Expect(p.GetDelay(i)).To(BeNumerically(">=", 0))
+	}
+}
+
+func TestRetry_Success(t *testing.T) {
+	g := NewWithT(t)
+
+	calls := 0
+
+	g.Expect(errorz.Retry(context.Background(), func(_ context.Context) error {
+		calls++
+		if calls < 3 {
+			return errorz.Errorf("e%v", calls)
+		}
+		return nil
+	}, errorz.RetrierBackoff(errorz.ConstantBackoff(0)))).To(Succeed())
+	g.Expect(calls).To(Equal(3))
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	g := NewWithT(t)
+
+	calls := 0
+
+	err := errorz.Retry(context.Background(), func(_ context.Context) error {
+		calls++
+		return errorz.Errorf("e%v", calls)
+	}, errorz.RetrierBackoff(errorz.ConstantBackoff(time.Millisecond)), errorz.RetrierMaxAttempts(3))
+	g.Expect(err).To(MatchError("retry failed after 3 attempt(s): e3"))
+	g.Expect(calls).To(Equal(3))
+	g.Expect(errorz.GetFrames(err)[0].ShortLocation).To(Equal("errorz_test.TestRetry_MaxAttempts"))
+
+	rErr, ok := errorz.As[*errorz.RetryError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(rErr.Unwrap()).To(MatchError("e3"))
+	g.Expect(rErr.GetAttempts()).To(HaveLen(3))
+
+	for i, a := range rErr.GetAttempts() {
+		g.Expect(a.Attempt).To(Equal(i + 1))
+		g.Expect(a.Err).To(MatchError(fmt.Sprintf("e%v", i+1)))
+		g.Expect(a.StartTime).ToNot(BeZero())
+		g.Expect(a.Duration).To(BeNumerically(">=", 0))
+
+		if i > 0 {
+			g.Expect(a.StartTime.Sub(rErr.GetAttempts()[i-1].StartTime)).To(BeNumerically(">=", time.Millisecond))
+		}
+	}
+}
+
+func TestRetry_Permanent(t *testing.T) {
+	g := NewWithT(t)
+
+	sentinelErr := fmt.Errorf("sentinel")
+	calls := 0
+
+	err := errorz.Retry(context.Background(), func(_ context.Context) error {
+		calls++
+		if calls == 2 {
+			return errorz.Permanent(errorz.Wrap(sentinelErr, errorz.WithHTTPStatus(http.StatusNotFound)))
+		}
+		return errorz.Errorf("e%v", calls)
+	}, errorz.RetrierBackoff(errorz.ConstantBackoff(0)))
+	g.Expect(err).To(MatchError("retry failed after 2 attempt(s): sentinel"))
+	g.Expect(calls).To(Equal(2))
+	g.Expect(errors.Is(err, sentinelErr)).To(BeTrue())
+	g.Expect(errorz.Classify(err).HTTPStatus).To(Equal(http.StatusNotFound))
+	g.Expect(errorz.DefaultRetryClassifier(err)).To(BeFalse())
+
+	calls = 0
+
+	err = errorz.Retry(context.Background(), func(_ context.Context) error {
+		calls++
+		return fmt.Errorf("e%v", calls)
+	}, errorz.RetrierClassifier(func(err error) bool { return false }))
+	g.Expect(err).To(MatchError("retry failed after 1 attempt(s): e1"))
+	g.Expect(calls).To(Equal(1))
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	g := NewWithT(t)
+
+	calls := 0
+
+	err := errorz.Retry(context.Background(), func(_ context.Context) error {
+		calls++
+		return errorz.Errorf("e%v", calls)
+	},
+		errorz.RetrierBackoff(errorz.ConstantBackoff(20*time.Millisecond)),
+		errorz.RetrierMaxAttempts(0),
+		errorz.RetrierMaxElapsed(30*time.Millisecond))
+	g.Expect(err).To(MatchError("retry failed after 2 attempt(s): e2"))
+	g.Expect(calls).To(Equal(2))
+}
+
+func TestRetry_Context(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err := errorz.Retry(ctx, func(ctx context.Context) error {
+		calls++
+		return errorz.Errorf("e%v", calls)
+	}, errorz.RetrierBackoff(errorz.ConstantBackoff(time.Hour)), errorz.RetrierMaxAttempts(0))
+	g.Expect(err).To(MatchError("context canceled: retry failed after 1 attempt(s): e1"))
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	g.Expect(calls).To(Equal(1))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+
+	err = errorz.Retry(ctx, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	g.Expect(err).To(MatchError("context canceled: retry failed before the first attempt"))
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	g.Expect(calls).To(Equal(0))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	calls = 0
+
+	err = errorz.Retry(ctx, func(ctx context.Context) error {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return errorz.Errorf("e%v", calls)
+	}, errorz.RetrierBackoff(errorz.ConstantBackoff(0)), errorz.RetrierMaxAttempts(0))
+	g.Expect(err).To(MatchError("context canceled: retry failed after 2 attempt(s): e2"))
+	g.Expect(calls).To(Equal(2))
+}
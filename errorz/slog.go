@@ -0,0 +1,172 @@
+package errorz
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+var (
+	_ slog.LogValuer    = (*wrappedError)(nil)
+	_ slog.Handler      = (*SlogHandler)(nil)
+	_ SlogHandlerOption = SlogHandlerOptionFunc(nil)
+)
+
+// LogValue implements the slog.LogValuer interface.
+// It returns a group with the message, the stack of errors (outermost first), the metadata, and the frame summaries.
+// Sensitive metadata values are redacted (see Redact).
+func (e *wrappedError) LogValue() slog.Value {
+	return slog.GroupValue(newSlogAttrs(e, -1)...)
+}
+
+// SlogHandlerOption describes a SlogHandler option.
+type SlogHandlerOption interface {
+	Apply(*SlogHandler)
+}
+
+// SlogHandlerOptionFunc describes a SlogHandler option.
+type SlogHandlerOptionFunc func(*SlogHandler)
+
+// Apply implements the SlogHandlerOption interface.
+func (f SlogHandlerOptionFunc) Apply(h *SlogHandler) {
+	f(h)
+}
+
+// SlogHandlerMaxFrames returns a SlogHandler option that limits the number of frames logged for each error.
+// A negative value (the default) means no limit.
+func SlogHandlerMaxFrames(maxFrames int) SlogHandlerOptionFunc {
+	return func(h *SlogHandler) {
+		h.maxFrames = maxFrames
+	}
+}
+
+// SlogHandler is a slog.Handler middleware that expands error attributes into groups containing the message, the
+// stack of errors, the metadata, and the frames (see GetFrames), before passing them to the next handler. Frames are
+// only included for wrapped errors, as other errors do not carry any.
+type SlogHandler struct {
+	next      slog.Handler
+	maxFrames int
+}
+
+// NewSlogHandler initializes a new SlogHandler.
+func NewSlogHandler(next slog.Handler, options ...SlogHandlerOption) *SlogHandler {
+	h := &SlogHandler{
+		next:      next,
+		maxFrames: -1,
+	}
+
+	for _, option := range options {
+		option.Apply(h)
+	}
+
+	return h
+}
+
+// Enabled implements the slog.Handler interface.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements the slog.Handler interface.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.expandAttr(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, nr)
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expandedAttrs := make([]slog.Attr, 0, len(attrs))
+
+	for _, a := range attrs {
+		expandedAttrs = append(expandedAttrs, h.expandAttr(a))
+	}
+
+	return &SlogHandler{
+		next:      h.next.WithAttrs(expandedAttrs),
+		maxFrames: h.maxFrames,
+	}
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{
+		next:      h.next.WithGroup(name),
+		maxFrames: h.maxFrames,
+	}
+}
+
+func (h *SlogHandler) expandAttr(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		groupAttrs := a.Value.Group()
+		expandedAttrs := make([]any, 0, len(groupAttrs))
+
+		for _, ga := range groupAttrs {
+			expandedAttrs = append(expandedAttrs, h.expandAttr(ga))
+		}
+
+		return slog.Group(a.Key, expandedAttrs...)
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok && !isNil(err) {
+			return slog.Attr{
+				Key:   a.Key,
+				Value: slog.GroupValue(newSlogAttrs(err, h.maxFrames)...),
+			}
+		}
+	}
+
+	return a
+}
+
+func newSlogAttrs(err error, maxFrames int) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("message", err.Error()),
+	}
+
+	if e, ok := err.(*wrappedError); ok {
+		if errs := e.getErrs(); len(errs) > 1 {
+			messages := make([]string, 0, len(errs))
+
+			for i := len(errs) - 1; i >= 0; i-- {
+				messages = append(messages, errs[i].Error())
+			}
+
+			attrs = append(attrs, slog.Any("errors", messages))
+		}
+	}
+
+	if md := Redact(GetAllMetadata(err)); len(md) > 0 {
+		keys := make([]string, 0, len(md))
+
+		for k := range md {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		mdAttrs := make([]any, 0, len(md))
+
+		for _, k := range keys {
+			mdAttrs = append(mdAttrs, slog.Any(k, md[k]))
+		}
+
+		attrs = append(attrs, slog.Group("metadata", mdAttrs...))
+	}
+
+	if _, ok := err.(*wrappedError); ok && maxFrames != 0 {
+		frames := GetFrames(err)
+
+		if maxFrames > 0 && len(frames) > maxFrames {
+			frames = frames[:maxFrames]
+		}
+
+		attrs = append(attrs, slog.Any("frames", frames.ToSummaries()))
+	}
+
+	return attrs
+}
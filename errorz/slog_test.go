@@ -0,0 +1,119 @@
+package errorz_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func newTestSlogLogger(h func(slog.Handler) slog.Handler) (*slog.Logger, func() map[string]any) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	return slog.New(h(handler)), func() map[string]any {
+		m := map[string]any{}
+		errorz.MaybeMustWrap(json.Unmarshal(buf.Bytes(), &m))
+		buf.Reset()
+		return m
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	g := NewWithT(t)
+
+	log, read := newTestSlogLogger(func(h slog.Handler) slog.Handler { return h })
+
+	log.Info("msg", "err", errorz.Wrap(fmt.Errorf("e"), errorz.WithMetadata("k", "v"), stringError("o1")))
+	g.Expect(read()).To(MatchAllKeys(Keys{
+		"level": Equal("INFO"),
+		"msg":   Equal("msg"),
+		"err": MatchAllKeys(Keys{
+			"message":  Equal("o1: e"),
+			"errors":   HaveExactElements("o1", "e"),
+			"metadata": Equal(map[string]any{"k": "v"}),
+			"frames":   ContainElement(HavePrefix("errorz_test.TestLogValue ")),
+		}),
+	}))
+
+	log.Info("msg", "err", errorz.Errorf("e"))
+	g.Expect(read()).To(HaveKeyWithValue("err", MatchAllKeys(Keys{
+		"message": Equal("e"),
+		"frames":  ContainElement(HavePrefix("errorz_test.TestLogValue ")),
+	})))
+
+	log.Info("msg", "err", fmt.Errorf("e"))
+	g.Expect(read()).To(HaveKeyWithValue("err", "e"))
+}
+
+func TestSlogHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	log, read := newTestSlogLogger(func(h slog.Handler) slog.Handler {
+		return errorz.NewSlogHandler(h, errorz.SlogHandlerMaxFrames(1))
+	})
+
+	g.Expect(log.Enabled(context.Background(), slog.LevelDebug)).To(BeFalse())
+
+	log.Info("msg", "err", errors.New("e"))
+	g.Expect(read()).To(HaveKeyWithValue("err", MatchAllKeys(Keys{
+		"message": Equal("e"),
+	})))
+
+	log.Info("msg", "err", errorz.Wrap(fmt.Errorf("e"), errorz.WithMetadata("k", "v")), "k", "v", "nilErr", error(nil))
+	g.Expect(read()).To(MatchAllKeys(Keys{
+		"level": Equal("INFO"),
+		"msg":   Equal("msg"),
+		"k":     Equal("v"),
+		"err": MatchAllKeys(Keys{
+			"message":  Equal("e"),
+			"metadata": Equal(map[string]any{"k": "v"}),
+			"frames":   HaveExactElements(HavePrefix("errorz_test.TestSlogHandler ")),
+		}),
+		"nilErr": BeNil(),
+	}))
+
+	log.
+		With("err1", errorz.Errorf("e1")).
+		WithGroup("g").
+		Info("msg", slog.Group("sg", "err2", fmt.Errorf("e2")))
+	g.Expect(read()).To(MatchAllKeys(Keys{
+		"level": Equal("INFO"),
+		"msg":   Equal("msg"),
+		"err1": MatchAllKeys(Keys{
+			"message": Equal("e1"),
+			"frames":  HaveLen(1),
+		}),
+		"g": MatchAllKeys(Keys{
+			"sg": MatchAllKeys(Keys{
+				"err2": MatchAllKeys(Keys{
+					"message": Equal("e2"),
+				}),
+			}),
+		}),
+	}))
+
+	log, read = newTestSlogLogger(func(h slog.Handler) slog.Handler {
+		return errorz.NewSlogHandler(h, errorz.SlogHandlerMaxFrames(0))
+	})
+
+	log.Info("msg", "err", errorz.Errorf("e"))
+	g.Expect(read()).To(HaveKeyWithValue("err", MatchAllKeys(Keys{
+		"message": Equal("e"),
+	})))
+}
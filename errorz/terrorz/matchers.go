@@ -0,0 +1,345 @@
+package terrorz
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+var (
+	_ types.GomegaMatcher = (*containErrorMatcher)(nil)
+	_ types.GomegaMatcher = (*haveFrameInPackageMatcher)(nil)
+	_ types.GomegaMatcher = (*haveLayerMessagesMatcher)(nil)
+	_ types.GomegaMatcher = (*haveRecoveredValueMatcher)(nil)
+)
+
+// ContainError succeeds if the actual error chain contains the expected error. The expected value can be an error,
+// which is compared using "errors.Is", or a matcher, which is run against every error in the chain.
+// On failure, it prints the whole actual chain.
+func ContainError(expected any) types.GomegaMatcher {
+	return &containErrorMatcher{
+		expected: expected,
+	}
+}
+
+// HaveFrameInPackage succeeds if the frames of the actual error include at least one in the given package, which can
+// be either a full package path or a short package name. The actual error must have been wrapped by errorz (see
+// "errorz.IsWrapped"), as other errors carry no frames.
+func HaveFrameInPackage(pkg string) types.GomegaMatcher {
+	return &haveFrameInPackageMatcher{
+		pkg: pkg,
+	}
+}
+
+// HaveLayerMessages succeeds if the layers of the actual error (see "errorz.GetLayers"), outermost first, have the
+// expected messages. Each expected value can be a string or a matcher run against the layer message.
+// On failure, it prints a diff of the expected and actual layers.
+func HaveLayerMessages(expected ...any) types.GomegaMatcher {
+	return &haveLayerMessagesMatcher{
+		expected: expected,
+	}
+}
+
+// HaveRecoveredValue succeeds if the actual error was created by "errorz.WrapRecover" from a value which was not an
+// error, and the value is equal to the expected one. The expected value can also be a matcher.
+func HaveRecoveredValue(expected any) types.GomegaMatcher {
+	return &haveRecoveredValueMatcher{
+		expected: expected,
+	}
+}
+
+type containErrorMatcher struct {
+	expected any
+}
+
+// Match implements the types.GomegaMatcher interface.
+func (m *containErrorMatcher) Match(actual any) (bool, error) {
+	actualErr, err := toError(actual)
+	if err != nil {
+		return false, err
+	}
+
+	switch expected := m.expected.(type) {
+	case types.GomegaMatcher:
+		for _, err := range getChain(actualErr) {
+			if ok, err := expected.Match(err); err == nil && ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	case error:
+		return errors.Is(actualErr, expected), nil
+	default:
+		return false, fmt.Errorf("ContainError must be passed an error or a matcher, got:\n%v", format.Object(m.expected, 1))
+	}
+}
+
+// FailureMessage implements the types.GomegaMatcher interface.
+func (m *containErrorMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf("Expected error chain\n%v\nto contain\n%v", formatChain(actual), formatExpected(m.expected))
+}
+
+// NegatedFailureMessage implements the types.GomegaMatcher interface.
+func (m *containErrorMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("Expected error chain\n%v\nnot to contain\n%v", formatChain(actual), formatExpected(m.expected))
+}
+
+type haveFrameInPackageMatcher struct {
+	pkg string
+}
+
+// Match implements the types.GomegaMatcher interface.
+func (m *haveFrameInPackageMatcher) Match(actual any) (bool, error) {
+	actualErr, err := toError(actual)
+	if err != nil {
+		return false, err
+	}
+
+	if !errorz.IsWrapped(actualErr) {
+		return false, fmt.Errorf("expected a wrapped error, got:\n%v", format.Object(actual, 1))
+	}
+
+	for _, frame := range errorz.GetFrames(actualErr) {
+		if frame.Package == m.pkg || frame.ShortPackage == m.pkg {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FailureMessage implements the types.GomegaMatcher interface.
+func (m *haveFrameInPackageMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf("Expected error frames\n%v\nto include a frame in package\n%v%v", formatFrames(actual), format.Indent, m.pkg)
+}
+
+// NegatedFailureMessage implements the types.GomegaMatcher interface.
+func (m *haveFrameInPackageMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("Expected error frames\n%v\nnot to include a frame in package\n%v%v", formatFrames(actual), format.Indent, m.pkg)
+}
+
+type haveLayerMessagesMatcher struct {
+	expected []any
+}
+
+// Match implements the types.GomegaMatcher interface.
+func (m *haveLayerMessagesMatcher) Match(actual any) (bool, error) {
+	actualErr, err := toError(actual)
+	if err != nil {
+		return false, err
+	}
+
+	layers := errorz.GetLayers(actualErr)
+
+	if len(layers) != len(m.expected) {
+		return false, nil
+	}
+
+	for i, layer := range layers {
+		ok, err := matchMessage(m.expected[i], layer.Error())
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// FailureMessage implements the types.GomegaMatcher interface.
+func (m *haveLayerMessagesMatcher) FailureMessage(actual any) string {
+	actualErr, _ := toError(actual)
+	layers := errorz.GetLayers(actualErr)
+	w := &strings.Builder{}
+
+	_, _ = w.WriteString("Expected error layers to match (- expected, + actual):")
+
+	for i := 0; i < max(len(layers), len(m.expected)); i++ {
+		switch {
+		case i >= len(layers):
+			_, _ = fmt.Fprintf(w, "\n%v- [%v] %v", format.Indent, i, formatExpectedMessage(m.expected[i]))
+		case i >= len(m.expected):
+			_, _ = fmt.Fprintf(w, "\n%v+ [%v] %q", format.Indent, i, layers[i].Error())
+		default:
+			if ok, err := matchMessage(m.expected[i], layers[i].Error()); err == nil && ok {
+				_, _ = fmt.Fprintf(w, "\n%v  [%v] %q", format.Indent, i, layers[i].Error())
+			} else {
+				_, _ = fmt.Fprintf(w, "\n%v- [%v] %v", format.Indent, i, formatExpectedMessage(m.expected[i]))
+				_, _ = fmt.Fprintf(w, "\n%v+ [%v] %q", format.Indent, i, layers[i].Error())
+			}
+		}
+	}
+
+	return w.String()
+}
+
+// NegatedFailureMessage implements the types.GomegaMatcher interface.
+func (m *haveLayerMessagesMatcher) NegatedFailureMessage(actual any) string {
+	actualErr, _ := toError(actual)
+	messages := make([]string, 0)
+
+	for _, layer := range errorz.GetLayers(actualErr) {
+		messages = append(messages, fmt.Sprintf("%v%q", format.Indent, layer.Error()))
+	}
+
+	return fmt.Sprintf("Expected error layers\n%v\nnot to match", strings.Join(messages, "\n"))
+}
+
+type haveRecoveredValueMatcher struct {
+	expected any
+}
+
+// Match implements the types.GomegaMatcher interface.
+func (m *haveRecoveredValueMatcher) Match(actual any) (bool, error) {
+	actualErr, err := toError(actual)
+	if err != nil {
+		return false, err
+	}
+
+	v, ok := errorz.GetValue(actualErr)
+	if !ok {
+		return false, nil
+	}
+
+	if expected, ok := m.expected.(types.GomegaMatcher); ok {
+		return expected.Match(v)
+	}
+
+	return reflect.DeepEqual(v, m.expected), nil
+}
+
+// FailureMessage implements the types.GomegaMatcher interface.
+func (m *haveRecoveredValueMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf("Expected recovered value\n%v\nto equal\n%v", formatRecoveredValue(actual), formatExpected(m.expected))
+}
+
+// NegatedFailureMessage implements the types.GomegaMatcher interface.
+func (m *haveRecoveredValueMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("Expected recovered value\n%v\nnot to equal\n%v", formatRecoveredValue(actual), formatExpected(m.expected))
+}
+
+func toError(actual any) (error, error) {
+	if actual == nil || reflect.ValueOf(actual).Kind() == reflect.Pointer && reflect.ValueOf(actual).IsNil() {
+		return nil, fmt.Errorf("expected an error, got nil")
+	}
+
+	err, ok := actual.(error)
+	if !ok {
+		return nil, fmt.Errorf("expected an error, got:\n%v", format.Object(actual, 1))
+	}
+
+	return err, nil
+}
+
+// getChain returns all the errors in the chain, depth-first, outermost first, flattening wrapped errors into their layers.
+func getChain(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if layers := errorz.GetLayers(err); len(layers) != 1 || layers[0] != err {
+		chain := make([]error, 0)
+
+		for _, layer := range layers {
+			chain = append(chain, getChain(layer)...)
+		}
+
+		return chain
+	}
+
+	chain := []error{err}
+
+	switch e := err.(type) {
+	case errorz.UnwrapMulti:
+		for _, err := range e.Unwrap() {
+			chain = append(chain, getChain(err)...)
+		}
+	case errorz.UnwrapSingle:
+		chain = append(chain, getChain(e.Unwrap())...)
+	}
+
+	return chain
+}
+
+func formatChain(actual any) string {
+	err, ok := actual.(error)
+	if !ok {
+		return format.Object(actual, 1)
+	}
+
+	lines := make([]string, 0)
+
+	for i, err := range getChain(err) {
+		lines = append(lines, fmt.Sprintf("%v[%v] %T: %q", format.Indent, i, err, err.Error()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatFrames(actual any) string {
+	err, ok := actual.(error)
+	if !ok {
+		return format.Object(actual, 1)
+	}
+
+	if !errorz.IsWrapped(err) {
+		return format.Indent + "<none>"
+	}
+
+	lines := make([]string, 0)
+
+	for _, frame := range errorz.GetFrames(err) {
+		lines = append(lines, format.Indent+frame.Summary)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatRecoveredValue(actual any) string {
+	err, ok := actual.(error)
+	if !ok {
+		return format.Object(actual, 1)
+	}
+
+	v, ok := errorz.GetValue(err)
+	if !ok {
+		return format.Indent + "<none>"
+	}
+
+	return format.Object(v, 1)
+}
+
+func formatExpected(expected any) string {
+	if err, ok := expected.(error); ok {
+		return fmt.Sprintf("%v%T: %q", format.Indent, err, err.Error())
+	}
+
+	return format.Object(expected, 1)
+}
+
+func formatExpectedMessage(expected any) string {
+	if s, ok := expected.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprintf("<%T>", expected)
+}
+
+func matchMessage(expected any, message string) (bool, error) {
+	switch expected := expected.(type) {
+	case string:
+		return expected == message, nil
+	case types.GomegaMatcher:
+		return expected.Match(message)
+	default:
+		return false, fmt.Errorf("HaveLayerMessages must be passed strings or matchers, got:\n%v", format.Object(expected, 1))
+	}
+}
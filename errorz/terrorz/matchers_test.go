@@ -0,0 +1,150 @@
+package terrorz_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+	. "github.com/ibrt/golang-lib/errorz/terrorz"
+)
+
+type testError struct {
+	k string
+}
+
+func (e *testError) Error() string {
+	return e.k
+}
+
+func TestContainError(t *testing.T) {
+	g := NewWithT(t)
+
+	e1 := &testError{k: "e"}
+	e2 := fmt.Errorf("o1: %w", fmt.Errorf("c"))
+	err := errorz.Wrap(e1, e2, errorz.WithCode("x"))
+
+	g.Expect(err).To(ContainError(e1))
+	g.Expect(err).To(ContainError(e2))
+	g.Expect(err).ToNot(ContainError(fmt.Errorf("e")))
+	g.Expect(err).To(ContainError(MatchError("c")))
+	g.Expect(err).ToNot(ContainError(MatchError("x")))
+	g.Expect(fmt.Errorf("x: %w", err)).To(ContainError(e1))
+
+	m := ContainError(&testError{k: "x"})
+	g.Expect(m.Match(err)).To(BeFalse())
+	g.Expect(m.FailureMessage(err)).To(Equal(
+		"Expected error chain\n" +
+			"    [0] *fmt.wrapError: \"o1: c\"\n" +
+			"    [1] *errors.errorString: \"c\"\n" +
+			"    [2] *terrorz_test.testError: \"e\"\n" +
+			"to contain\n" +
+			"    *terrorz_test.testError: \"x\""))
+	g.Expect(m.NegatedFailureMessage(err)).To(HavePrefix("Expected error chain\n    [0] *fmt.wrapError: \"o1: c\"\n"))
+
+	_, mErr := m.Match(nil)
+	g.Expect(mErr).To(MatchError("expected an error, got nil"))
+	_, mErr = m.Match("x")
+	g.Expect(mErr).To(MatchError(HavePrefix("expected an error, got:\n")))
+	_, mErr = ContainError("x").Match(err)
+	g.Expect(mErr).To(MatchError(HavePrefix("ContainError must be passed an error or a matcher, got:\n")))
+}
+
+func TestHaveFrameInPackage(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.Errorf("e")
+	g.Expect(err).To(HaveFrameInPackage("terrorz_test"))
+	g.Expect(err).To(HaveFrameInPackage("github.com/ibrt/golang-lib/errorz/terrorz_test"))
+	g.Expect(err).To(HaveFrameInPackage("testing"))
+	g.Expect(err).ToNot(HaveFrameInPackage("errorz"))
+
+	m := HaveFrameInPackage("other")
+	g.Expect(m.Match(err)).To(BeFalse())
+	g.Expect(m.FailureMessage(err)).To(And(
+		HavePrefix("Expected error frames\n    terrorz_test.TestHaveFrameInPackage ("),
+		HaveSuffix("\nto include a frame in package\n    other")))
+	g.Expect(m.NegatedFailureMessage(err)).To(HaveSuffix("\nnot to include a frame in package\n    other"))
+
+	_, mErr := m.Match(nil)
+	g.Expect(mErr).To(MatchError("expected an error, got nil"))
+
+	plainErr := fmt.Errorf("plain")
+	ok, mErr := HaveFrameInPackage("terrorz_test").Match(plainErr)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(mErr).To(MatchError(HavePrefix("expected a wrapped error, got:\n")))
+	g.Expect(m.FailureMessage(plainErr)).To(Equal("Expected error frames\n    <none>\nto include a frame in package\n    other"))
+
+	_, mErr = m.Match(fmt.Errorf("x: %w", err))
+	g.Expect(mErr).To(MatchError(HavePrefix("expected a wrapped error, got:\n")))
+}
+
+func TestHaveLayerMessages(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.Wrap(&testError{k: "e"}, fmt.Errorf("o1"), fmt.Errorf("o2"))
+	g.Expect(err).To(HaveLayerMessages("o2", "o1", "e"))
+	g.Expect(err).To(HaveLayerMessages("o2", HavePrefix("o"), "e"))
+	g.Expect(err).ToNot(HaveLayerMessages("o2", "o1"))
+	g.Expect(err).ToNot(HaveLayerMessages("o2", "x", "e"))
+	g.Expect(fmt.Errorf("e")).To(HaveLayerMessages("e"))
+
+	m := HaveLayerMessages("o2", "x")
+	g.Expect(m.Match(err)).To(BeFalse())
+	g.Expect(m.FailureMessage(err)).To(Equal(
+		"Expected error layers to match (- expected, + actual):\n" +
+			"      [0] \"o2\"\n" +
+			"    - [1] \"x\"\n" +
+			"    + [1] \"o1\"\n" +
+			"    + [2] \"e\""))
+
+	m = HaveLayerMessages("o2", "o1", "e", HavePrefix("x"))
+	g.Expect(m.Match(err)).To(BeFalse())
+	g.Expect(m.FailureMessage(err)).To(Equal(
+		"Expected error layers to match (- expected, + actual):\n" +
+			"      [0] \"o2\"\n" +
+			"      [1] \"o1\"\n" +
+			"      [2] \"e\"\n" +
+			"    - [3] <*matchers.HavePrefixMatcher>"))
+	g.Expect(m.NegatedFailureMessage(err)).To(Equal(
+		"Expected error layers\n" +
+			"    \"o2\"\n" +
+			"    \"o1\"\n" +
+			"    \"e\"\n" +
+			"not to match"))
+
+	_, mErr := HaveLayerMessages(1).Match(fmt.Errorf("e"))
+	g.Expect(mErr).To(MatchError(HavePrefix("HaveLayerMessages must be passed strings or matchers, got:\n")))
+}
+
+func TestHaveRecoveredValue(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.WrapRecover(map[string]int{"k": 1})
+	g.Expect(err).To(HaveRecoveredValue(map[string]int{"k": 1}))
+	g.Expect(err).To(HaveRecoveredValue(HaveKeyWithValue("k", 1)))
+	g.Expect(err).ToNot(HaveRecoveredValue(map[string]int{"k": 2}))
+	g.Expect(errorz.Errorf("e")).ToNot(HaveRecoveredValue(nil))
+
+	m := HaveRecoveredValue("x")
+	g.Expect(m.Match(errorz.WrapRecover("v"))).To(BeFalse())
+	g.Expect(m.FailureMessage(errorz.WrapRecover("v"))).To(Equal(
+		"Expected recovered value\n" +
+			"    <string>: v\n" +
+			"to equal\n" +
+			"    <string>: x"))
+	g.Expect(m.FailureMessage(errorz.Errorf("e"))).To(Equal(
+		"Expected recovered value\n" +
+			"    <none>\n" +
+			"to equal\n" +
+			"    <string>: x"))
+	g.Expect(m.NegatedFailureMessage(errorz.WrapRecover("x"))).To(Equal(
+		"Expected recovered value\n" +
+			"    <string>: x\n" +
+			"not to equal\n" +
+			"    <string>: x"))
+
+	_, mErr := m.Match(nil)
+	g.Expect(mErr).To(MatchError("expected an error, got nil"))
+}
@@ -0,0 +1,97 @@
+package errorz
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	traceStackDepth = 16
+)
+
+var (
+	wrapTraceEnabled = &atomic.Bool{}
+)
+
+// SetWrapTraceEnabled enables or disables wrap tracing. When enabled, every call to Wrap (and related functions)
+// records its call site alongside the outer errors it added, building a "return trace" that shows how the error
+// propagated. Only Wrap calls made while tracing is enabled are recorded. It is disabled by default, as it adds some
+// overhead to every Wrap call.
+func SetWrapTraceEnabled(enabled bool) {
+	wrapTraceEnabled.Store(enabled)
+}
+
+// TraceEntry describes a Wrap call recorded while wrap tracing was enabled.
+type TraceEntry struct {
+	Frame  *Frame
+	Errors []error
+}
+
+// GetTrace returns the Wrap calls recorded on the error, in the order in which they happened. The first entry refers
+// to the call that wrapped the original error. It returns nil if the error is not wrapped or no call was recorded.
+func GetTrace(err error) []*TraceEntry {
+	e, ok := err.(*wrappedError)
+	if !ok {
+		return nil
+	}
+
+	var entries []*TraceEntry
+
+	for _, te := range e.getTrace() {
+		entries = append(entries, &TraceEntry{
+			Frame:  te.getFrame(),
+			Errors: slices.Clone(te.errs),
+		})
+	}
+
+	return entries
+}
+
+// traceEntry describes a Wrap call, lazily symbolizing its call site.
+type traceEntry struct {
+	once  *sync.Once
+	pcs   []uintptr
+	frame *Frame
+	errs  []error
+}
+
+// newTraceEntry captures the program counters of the caller of Wrap and a few of its callers.
+func newTraceEntry() *traceEntry {
+	pcs := make([]uintptr, traceStackDepth)
+
+	return &traceEntry{
+		once: &sync.Once{},
+		pcs:  pcs[:runtime.Callers(3, pcs)],
+	}
+}
+
+// getFrame returns the first frame outside of this package, i.e. the call site.
+func (te *traceEntry) getFrame() *Frame {
+	te.once.Do(func() {
+		if len(te.pcs) > 0 {
+			callersFrames := runtime.CallersFrames(te.pcs)
+
+			for {
+				callerFrame, more := callersFrames.Next()
+				frame := NewFrame(callerFrame.Function, callerFrame.File, callerFrame.Line)
+
+				if frame.ShortPackage != "errorz" {
+					te.frame = frame
+					break
+				}
+
+				if !more {
+					break
+				}
+			}
+		}
+
+		if te.frame == nil {
+			te.frame = NewFrame("", "", 0)
+		}
+	})
+
+	return te.frame
+}
@@ -0,0 +1,70 @@
+package errorz_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+func traceInner() error {
+	return errorz.Errorf("e")
+}
+
+func traceMiddle() error {
+	return errorz.Wrap(traceInner(), fmt.Errorf("o1"), errorz.WithCode("c"))
+}
+
+func traceOuter() error {
+	return errorz.MaybeWrap(traceMiddle())
+}
+
+func TestTrace(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(errorz.GetTrace(traceOuter())).To(BeNil())
+	g.Expect(errorz.GetTrace(fmt.Errorf("e"))).To(BeNil())
+
+	errorz.SetWrapTraceEnabled(true)
+	defer errorz.SetWrapTraceEnabled(false)
+
+	err := errorz.Wrap(traceOuter(), fmt.Errorf("o2"), fmt.Errorf("o3"))
+	g.Expect(err).To(MatchError("o3: o2: o1: e"))
+
+	trace := errorz.GetTrace(err)
+	g.Expect(trace).To(HaveLen(4))
+
+	g.Expect(trace[0].Frame.ShortLocation).To(Equal("errorz_test.traceInner"))
+	g.Expect(trace[0].Errors).To(HaveLen(1))
+	g.Expect(trace[0].Errors[0]).To(MatchError("e"))
+
+	g.Expect(trace[1].Frame.ShortLocation).To(Equal("errorz_test.traceMiddle"))
+	g.Expect(trace[1].Errors).To(HaveLen(1))
+	g.Expect(trace[1].Errors[0]).To(MatchError("o1"))
+
+	g.Expect(trace[2].Frame.ShortLocation).To(Equal("errorz_test.traceOuter"))
+	g.Expect(trace[2].Errors).To(BeEmpty())
+
+	g.Expect(trace[3].Frame.ShortLocation).To(Equal("errorz_test.TestTrace"))
+	g.Expect(trace[3].Errors).To(HaveLen(2))
+	g.Expect(trace[3].Errors[0]).To(MatchError("o2"))
+	g.Expect(trace[3].Errors[1]).To(MatchError("o3"))
+
+	g.Expect(errorz.GetFrames(err)[0].ShortLocation).To(Equal("errorz_test.traceInner"))
+
+	dump := errorz.SDump(err)
+	g.Expect(dump).To(HavePrefix("(errorz.dump)"))
+	g.Expect(dump).To(ContainSubstring("Metadata: (map[string]interface {}) (len=1) {"))
+	g.Expect(dump).To(ContainSubstring("Trace: ([]string) (len=4) {"))
+	g.Expect(dump).To(MatchRegexp(`"errorz_test\.traceInner \(.*trace_test\.go:\d+\): \\"e\\"",`))
+	g.Expect(dump).To(MatchRegexp(`"errorz_test\.traceMiddle \(.*trace_test\.go:\d+\): \\"o1\\"",`))
+	g.Expect(dump).To(MatchRegexp(`"errorz_test\.traceOuter \(.*trace_test\.go:\d+\)",`))
+	g.Expect(dump).To(MatchRegexp(`"errorz_test\.TestTrace \(.*trace_test\.go:\d+\): \\"o2\\", \\"o3\\""`))
+
+	dump = errorz.SDump(traceInner())
+	g.Expect(dump).To(HavePrefix("(errorz.dump)"))
+	g.Expect(dump).ToNot(ContainSubstring("Metadata:"))
+	g.Expect(dump).To(ContainSubstring("Trace: ([]string) (len=1) {"))
+}
@@ -3,6 +3,8 @@ package errorz
 import (
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
@@ -29,8 +31,9 @@ type AsHelper interface {
 }
 
 var (
-	_ error       = (*valueError)(nil)
-	_ UnwrapMulti = (*valueError)(nil)
+	_ error         = (*valueError)(nil)
+	_ fmt.Formatter = (*valueError)(nil)
+	_ UnwrapMulti   = (*valueError)(nil)
 )
 
 type valueError struct {
@@ -38,8 +41,22 @@ type valueError struct {
 }
 
 // Error implements the error interface.
+// Sensitive values are redacted (see Redact).
 func (e *valueError) Error() string {
-	return fmt.Sprintf("%v", e.Value)
+	return fmt.Sprintf("%v", Redact(e.Value))
+}
+
+// Format implements the fmt.Formatter interface.
+// It supports "%s", "%v", "%q", and "%+v" (which formats the value using "%+v").
+func (e *valueError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = fmt.Fprintf(s, "%+v", Redact(e.Value))
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
 }
 
 // Unwrap implements the UnwrapMulti interface.
@@ -62,16 +79,20 @@ func (e *valueError) Unwrap() []error {
 }
 
 var (
-	_ error       = (*wrappedError)(nil)
-	_ IsHelper    = (*wrappedError)(nil)
-	_ AsHelper    = (*wrappedError)(nil)
-	_ UnwrapMulti = (*wrappedError)(nil)
+	_ error         = (*wrappedError)(nil)
+	_ fmt.Formatter = (*wrappedError)(nil)
+	_ IsHelper      = (*wrappedError)(nil)
+	_ AsHelper      = (*wrappedError)(nil)
+	_ UnwrapMulti   = (*wrappedError)(nil)
 )
 
 type wrappedError struct {
-	m      *sync.Mutex
-	errs   []error
-	frames Frames
+	m        *sync.Mutex
+	errs     []error
+	stack    *stack
+	metadata map[string]any
+	trace    []*traceEntry
+	parent   *wrappedError
 }
 
 // Error implements the error interface.
@@ -91,12 +112,46 @@ func (e *wrappedError) Error() string {
 	return w.String()
 }
 
+// Format implements the fmt.Formatter interface.
+// It supports "%s", "%v", "%q", and "%+v", which prints the message, followed by each error in the stack (outermost
+// first, if more than one), followed by the frames, using the same layout as "github.com/pkg/errors".
+func (e *wrappedError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, e.Error())
+
+		if errs := e.getErrs(); len(errs) > 1 {
+			for i := len(errs) - 1; i >= 0; i-- {
+				_, _ = fmt.Fprintf(s, "\n    %v", errs[i].Error())
+			}
+		}
+
+		for _, frame := range e.stack.getFrames() {
+			if frame.Location != "" && frame.FileAndLine != "" {
+				_, _ = fmt.Fprintf(s, "\n%v\n\t%v", frame.Location, frame.FileAndLine)
+			} else {
+				_, _ = fmt.Fprintf(s, "\n%v", frame.Summary)
+			}
+		}
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
+
 // Is provides interoperability with "errors.Is".
 func (e *wrappedError) Is(target error) bool {
 	if e == nil || target == nil {
 		return e == target
 	}
 
+	for p := e.parent; p != nil; p = p.parent {
+		if p == target {
+			return true
+		}
+	}
+
 	e.m.Lock()
 	defer e.m.Unlock()
 
@@ -127,3 +182,71 @@ func (e *wrappedError) Unwrap() []error {
 	errs := slices.Clone(e.errs)
 	return errs[1:]
 }
+
+// clone returns a copy of the wrapped error which shares its frames, remembering it as parent.
+func (e *wrappedError) clone() *wrappedError {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return &wrappedError{
+		m:        &sync.Mutex{},
+		errs:     slices.Clone(e.errs),
+		stack:    e.stack,
+		metadata: maps.Clone(e.metadata),
+		trace:    slices.Clone(e.trace),
+		parent:   e,
+	}
+}
+
+func (e *wrappedError) getErrs() []error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return slices.Clone(e.errs)
+}
+
+func (e *wrappedError) getTrace() []*traceEntry {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return slices.Clone(e.trace)
+}
+
+func (e *wrappedError) getMetadata() map[string]any {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return maps.Clone(e.metadata)
+}
+
+// walk visits err and all the errors it wraps (depth-first, outermost first) until f returns false.
+func walk(err error, f func(err error) bool) bool {
+	if err == nil {
+		return true
+	}
+
+	if !f(err) {
+		return false
+	}
+
+	switch e := err.(type) {
+	case *wrappedError:
+		errs := e.getErrs()
+
+		for i := len(errs) - 1; i >= 0; i-- {
+			if !walk(errs[i], f) {
+				return false
+			}
+		}
+	case UnwrapMulti:
+		for _, err := range e.Unwrap() {
+			if !walk(err, f) {
+				return false
+			}
+		}
+	case UnwrapSingle:
+		return walk(e.Unwrap(), f)
+	}
+
+	return true
+}
@@ -104,9 +104,9 @@ func TestWrappedError(t *testing.T) {
 
 	e1 := fmt.Errorf("e")
 	err := &wrappedError{
-		m:      &sync.Mutex{},
-		errs:   []error{e1},
-		frames: GetFrames(nil),
+		m:     &sync.Mutex{},
+		errs:  []error{e1},
+		stack: newStack(),
 	}
 	g.Expect(err.Error()).To(Equal("e"))
 	g.Expect(err.Unwrap()).To(BeEmpty())
@@ -125,9 +125,9 @@ func TestWrappedError(t *testing.T) {
 	}
 
 	err = &wrappedError{
-		m:      &sync.Mutex{},
-		errs:   []error{stringError("e")},
-		frames: GetFrames(nil),
+		m:     &sync.Mutex{},
+		errs:  []error{stringError("e")},
+		stack: newStack(),
 	}
 	g.Expect(err.Error()).To(Equal("e"))
 	g.Expect(err.Unwrap()).To(BeEmpty())
@@ -153,9 +153,9 @@ func TestWrappedError(t *testing.T) {
 
 	e2 := &structError{k: "e"}
 	err = &wrappedError{
-		m:      &sync.Mutex{},
-		errs:   []error{e2},
-		frames: GetFrames(nil),
+		m:     &sync.Mutex{},
+		errs:  []error{e2},
+		stack: newStack(),
 	}
 	g.Expect(err.Error()).To(Equal("e"))
 	g.Expect(err.Unwrap()).To(BeEmpty())
@@ -184,9 +184,9 @@ func TestWrappedError(t *testing.T) {
 	e4 := fmt.Errorf("o1")
 	e5 := stringError("o2")
 	err = &wrappedError{
-		m:      &sync.Mutex{},
-		errs:   []error{e3, e4, e5},
-		frames: GetFrames(nil),
+		m:     &sync.Mutex{},
+		errs:  []error{e3, e4, e5},
+		stack: newStack(),
 	}
 	g.Expect(err.Error()).To(Equal("o2: o1: e"))
 	g.Expect(err.Unwrap()).To(HaveExactElements(e4, e5))
@@ -220,3 +220,68 @@ func TestWrappedError(t *testing.T) {
 	g.Expect((*wrappedError)(nil).As(fmt.Errorf(""))).To(BeFalse())
 	g.Expect((*wrappedError)(nil).Unwrap()).To(BeNil())
 }
+
+func TestStack(t *testing.T) {
+	g := NewWithT(t)
+
+	s := newStack()
+	g.Expect(s.pcs).ToNot(BeEmpty())
+	g.Expect(s.frames).To(BeNil())
+	frames := s.getFrames()
+	g.Expect(frames).ToNot(BeEmpty())
+	g.Expect(frames[0].ShortLocation).To(Equal("testing.tRunner")) // frames in package "errorz" are skipped
+	g.Expect(s.getFrames()).To(Equal(frames))
+
+	s = newSymbolizedStack(Frames{{Summary: "f1"}})
+	g.Expect(s.pcs).To(BeNil())
+	g.Expect(s.getFrames()).To(Equal(Frames{{Summary: "f1"}}))
+
+	g.Expect((&stack{once: &sync.Once{}}).getFrames()).To(BeEmpty())
+
+	var deepStack func(depth int) *stack
+	deepStack = func(depth int) *stack {
+		if depth == 0 {
+			return newStack()
+		}
+		return deepStack(depth - 1)
+	}
+
+	g.Expect(len(deepStack(initialStackDepth * 2).pcs)).To(BeNumerically(">", initialStackDepth*2))
+	g.Expect(len(deepStack(maxStackDepth * 2).pcs)).To(Equal(maxStackDepth))
+}
+
+func TestValueError_Format(t *testing.T) {
+	g := NewWithT(t)
+
+	err := &valueError{
+		Value: struct{ K string }{K: "v"},
+	}
+
+	g.Expect(fmt.Sprintf("%s", err)).To(Equal("{v}"))
+	g.Expect(fmt.Sprintf("%v", err)).To(Equal("{v}"))
+	g.Expect(fmt.Sprintf("%q", err)).To(Equal(`"{v}"`))
+	g.Expect(fmt.Sprintf("%+v", err)).To(Equal("{K:v}"))
+	g.Expect(fmt.Sprintf("%d", err)).To(Equal("{v}"))
+}
+
+func TestWrappedError_Format(t *testing.T) {
+	g := NewWithT(t)
+
+	err := &wrappedError{
+		m:     &sync.Mutex{},
+		errs:  []error{fmt.Errorf("e")},
+		stack: newSymbolizedStack(Frames{NewFrame("a/b.c", "f.go", 10), NewFrame("", "", 0)}),
+	}
+
+	g.Expect(fmt.Sprintf("%s", err)).To(Equal("e"))
+	g.Expect(fmt.Sprintf("%v", err)).To(Equal("e"))
+	g.Expect(fmt.Sprintf("%q", err)).To(Equal(`"e"`))
+	g.Expect(fmt.Sprintf("%+v", err)).To(Equal("e\na/b.c\n\tf.go:10\n<unknown>"))
+	g.Expect(fmt.Sprintf("%d", err)).To(Equal("e"))
+
+	err.errs = append(err.errs, stringError("o1"), stringError("o2"))
+	g.Expect(fmt.Sprintf("%s", err)).To(Equal("o2: o1: e"))
+	g.Expect(fmt.Sprintf("%+v", err)).To(Equal("o2: o1: e\n    o2\n    o1\n    e\na/b.c\n\tf.go:10\n<unknown>"))
+
+	g.Expect(fmt.Sprintf("%+v", Errorf("e"))).To(MatchRegexp(`^e\ntesting\.tRunner\n\t.+/testing\.go:\d+\nruntime\.goexit\n\t.+:\d+$`))
+}
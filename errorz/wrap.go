@@ -3,21 +3,75 @@ package errorz
 import (
 	"errors"
 	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
-// Wrap wraps the given errors.
+var (
+	_ WrapOption = wrapOptionFunc(nil)
+)
+
+var (
+	copyOnWrapEnabled = &atomic.Bool{}
+)
+
+// WrapOption describes an option that can be passed to Wrap (and related functions) alongside the outer errors.
+// Options are applied to the wrapped error, but they are not added to its stack of errors.
+type WrapOption interface {
+	error
+	applyWrapOption(e *wrappedError)
+}
+
+type wrapOptionFunc func(e *wrappedError)
+
+// Error implements the error interface.
+func (f wrapOptionFunc) Error() string {
+	return "wrap option"
+}
+
+func (f wrapOptionFunc) applyWrapOption(e *wrappedError) {
+	f(e)
+}
+
+// SetCopyOnWrapEnabled enables or disables copy-on-wrap. By default, wrapping an already wrapped error appends the outer
+// errors to it in place, so every holder of that error sees them. When copy-on-wrap is enabled, every Wrap call
+// returns a new wrapped error instead, leaving the original untouched. The copy shares the frames of the original,
+// and matches it using "errors.Is".
+func SetCopyOnWrapEnabled(enabled bool) {
+	copyOnWrapEnabled.Store(enabled)
+}
+
+// Wrap wraps the given errors. Any WrapOption found among the outer errors is applied to the wrapped error.
+// The wrapped error is modified in place, unless copy-on-wrap is enabled (see SetCopyOnWrapEnabled).
+// It panics if "err" is nil or a WrapOption.
 func Wrap(err error, outerErrs ...error) error {
 	if err == nil {
 		MustErrorf("err is nil")
 	}
 
+	if _, ok := err.(WrapOption); ok {
+		MustErrorf("err is a WrapOption")
+	}
+
+	var te *traceEntry
+	if wrapTraceEnabled.Load() {
+		te = newTraceEntry()
+	}
+
 	wErr, ok := err.(*wrappedError)
-	if !ok {
+	switch {
+	case ok && copyOnWrapEnabled.Load():
+		wErr = wErr.clone()
+	case !ok:
 		wErr = &wrappedError{
-			m:      &sync.Mutex{},
-			errs:   []error{err},
-			frames: GetFrames(nil),
+			m:     &sync.Mutex{},
+			errs:  []error{err},
+			stack: newStack(),
+		}
+
+		if te != nil {
+			te.errs = append(te.errs, err)
 		}
 	}
 
@@ -25,11 +79,24 @@ func Wrap(err error, outerErrs ...error) error {
 	defer wErr.m.Unlock()
 
 	for _, outerErr := range outerErrs {
-		if outerErr != nil {
+		switch o := outerErr.(type) {
+		case nil:
+			// ignore
+		case WrapOption:
+			o.applyWrapOption(wErr)
+		default:
 			wErr.errs = append(wErr.errs, outerErr)
+
+			if te != nil {
+				te.errs = append(te.errs, outerErr)
+			}
 		}
 	}
 
+	if te != nil {
+		wErr.trace = append(wErr.trace, te)
+	}
+
 	return wErr
 }
 
@@ -85,6 +152,39 @@ func As[T any](err error) (T, bool) {
 	return t, errors.As(err, &t)
 }
 
+// IsWrapped returns true if the error was wrapped by Wrap (or a related function), i.e. if it carries its own frames
+// (see GetFrames). Errors wrapping it (e.g. using "fmt.Errorf") are not considered wrapped.
+func IsWrapped(err error) bool {
+	_, ok := err.(*wrappedError)
+	return ok
+}
+
+// GetLayers returns the errors wrapped together by Wrap, outermost first, or a slice containing only the error itself if
+// it is not wrapped. It returns nil if the error is nil.
+func GetLayers(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(*wrappedError); ok {
+		errs := e.getErrs()
+		slices.Reverse(errs)
+		return errs
+	}
+
+	return []error{err}
+}
+
+// GetValue returns the value of the first error in the chain that was created by WrapRecover from a recovered value
+// which was not an error. It returns false if no such error is found.
+func GetValue(err error) (any, bool) {
+	if vErr, ok := As[*valueError](err); ok {
+		return vErr.Value, true
+	}
+
+	return nil, false
+}
+
 func isNil(x any) bool {
 	if x == nil {
 		return true
@@ -3,6 +3,7 @@ package errorz_test
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -42,6 +43,7 @@ func TestWrap(t *testing.T) {
 	g.Expect(err.(errorz.UnwrapMulti).Unwrap()).To(HaveExactElements(e2, e3))
 
 	g.Expect(func() { _ = errorz.Wrap(nil) }).To(PanicWith(MatchError("err is nil")))
+	g.Expect(func() { _ = errorz.Wrap(errorz.WithCode("c"), e2) }).To(PanicWith(MatchError("err is a WrapOption")))
 }
 
 func TestMaybeWrap(t *testing.T) {
@@ -56,6 +58,8 @@ func TestMaybeWrap(t *testing.T) {
 
 	err = errorz.MaybeWrap(nil)
 	g.Expect(err).To(BeNil())
+
+	g.Expect(func() { _ = errorz.MaybeWrap(errorz.WithCode("c")) }).To(PanicWith(MatchError("err is a WrapOption")))
 }
 
 func TestMustWrap(t *testing.T) {
@@ -242,3 +246,113 @@ func TestAs(t *testing.T) {
 		g.Expect(e).To(Equal(e3))
 	}
 }
+
+func TestIsWrapped(t *testing.T) {
+	g := NewWithT(t)
+
+	err := errorz.Errorf("e")
+	g.Expect(errorz.IsWrapped(err)).To(BeTrue())
+	g.Expect(errorz.IsWrapped(errorz.Wrap(fmt.Errorf("e")))).To(BeTrue())
+	g.Expect(errorz.IsWrapped(fmt.Errorf("x: %w", err))).To(BeFalse())
+	g.Expect(errorz.IsWrapped(fmt.Errorf("e"))).To(BeFalse())
+	g.Expect(errorz.IsWrapped(nil)).To(BeFalse())
+}
+
+func TestGetLayers(t *testing.T) {
+	g := NewWithT(t)
+
+	e1 := &structError{k: "e"}
+	e2 := fmt.Errorf("o1")
+	e3 := stringError("o2")
+
+	g.Expect(errorz.GetLayers(nil)).To(BeNil())
+	g.Expect(errorz.GetLayers(e1)).To(Equal([]error{e1}))
+	g.Expect(errorz.GetLayers(errorz.Wrap(e1))).To(Equal([]error{e1}))
+	g.Expect(errorz.GetLayers(errorz.Wrap(e1, e2, errorz.WithCode("c"), e3))).To(Equal([]error{e3, e2, e1}))
+}
+
+func TestGetValue(t *testing.T) {
+	g := NewWithT(t)
+
+	v, ok := errorz.GetValue(errorz.WrapRecover(map[string]int{"k": 1}, fmt.Errorf("o")))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal(map[string]int{"k": 1}))
+
+	v, ok = errorz.GetValue(fmt.Errorf("x: %w", errorz.WrapRecover("v")))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal("v"))
+
+	v, ok = errorz.GetValue(errorz.WrapRecover(fmt.Errorf("e")))
+	g.Expect(ok).To(BeFalse())
+	g.Expect(v).To(BeNil())
+}
+
+func TestWrap_CopyOnWrap(t *testing.T) {
+	g := NewWithT(t)
+
+	errorz.SetCopyOnWrapEnabled(true)
+	defer errorz.SetCopyOnWrapEnabled(false)
+
+	e1 := &structError{k: "e"}
+	base := errorz.Wrap(e1, errorz.WithMetadata("k", "base"))
+
+	err1 := errorz.Wrap(base, fmt.Errorf("o1"), errorz.WithMetadata("k", "err1"))
+	err2 := errorz.Wrap(base, fmt.Errorf("o2"))
+
+	g.Expect(base).To(MatchError("e"))
+	g.Expect(err1).To(MatchError("o1: e"))
+	g.Expect(err2).To(MatchError("o2: e"))
+
+	g.Expect(errorz.GetAllMetadata(base)).To(Equal(map[string]any{"k": "base"}))
+	g.Expect(errorz.GetAllMetadata(err1)).To(Equal(map[string]any{"k": "err1"}))
+	g.Expect(errorz.GetAllMetadata(err2)).To(Equal(map[string]any{"k": "base"}))
+
+	g.Expect(errors.Is(err1, base)).To(BeTrue())
+	g.Expect(errors.Is(err1, e1)).To(BeTrue())
+	g.Expect(errors.Is(errorz.Wrap(err1), base)).To(BeTrue())
+	g.Expect(errors.Is(base, err1)).To(BeFalse())
+	g.Expect(errors.Is(err1, err2)).To(BeFalse())
+
+	g.Expect(errorz.GetFrames(err1)[0]).To(BeIdenticalTo(errorz.GetFrames(base)[0]))
+	g.Expect(errorz.GetFrames(err2)[0]).To(BeIdenticalTo(errorz.GetFrames(base)[0]))
+}
+
+func TestWrap_CopyOnWrap_Concurrent(t *testing.T) {
+	for _, copyOnWrap := range []bool{false, true} {
+		t.Run(fmt.Sprintf("%v", copyOnWrap), func(t *testing.T) {
+			g := NewWithT(t)
+
+			errorz.SetCopyOnWrapEnabled(copyOnWrap)
+			defer errorz.SetCopyOnWrapEnabled(false)
+
+			base := errorz.Errorf("e")
+			errs := make([]error, 100)
+			wg := &sync.WaitGroup{}
+
+			for i := range errs {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+					errs[i] = errorz.Wrap(base, fmt.Errorf("o%v", i))
+				}()
+			}
+
+			wg.Wait()
+
+			if copyOnWrap {
+				g.Expect(base).To(MatchError("e"))
+
+				for i, err := range errs {
+					g.Expect(err).To(MatchError(fmt.Sprintf("o%v: e", i)))
+				}
+			} else {
+				g.Expect(errorz.GetLayers(base)).To(HaveLen(101))
+
+				for _, err := range errs {
+					g.Expect(err).To(BeIdenticalTo(base))
+				}
+			}
+		})
+	}
+}
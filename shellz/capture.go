@@ -0,0 +1,146 @@
+package shellz
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// capture holds the ring buffers retaining the tail of the output of a command (see Command.SetCaptureSize).
+// Buffers for streams which are not captured are nil.
+type capture struct {
+	stdout   *ringBuffer
+	stderr   *ringBuffer
+	terminal bool
+}
+
+func (c *Command) newCapture() *capture {
+	cp := &capture{
+		terminal: c.captureTTY,
+	}
+
+	if c.captureSize > 0 {
+		cp.stderr = newRingBuffer(c.captureSize)
+
+		if c.captureOut {
+			cp.stdout = newRingBuffer(c.captureSize)
+		}
+	}
+
+	return cp
+}
+
+// wrapStdout returns a writer that writes to "w" (if not nil) and to the standard output buffer (if captured).
+// If "w" is a terminal, it is returned as is unless terminal capturing is enabled (see Command.SetCaptureTerminal).
+func (cp *capture) wrapStdout(w io.Writer) io.Writer {
+	if !cp.terminal && isTerminal(w) {
+		return w
+	}
+
+	return cp.stdout.wrap(w)
+}
+
+// wrapStderr returns a writer that writes to "w" (if not nil) and to the standard error buffer (if captured).
+// If "w" is a terminal, it is returned as is unless terminal capturing is enabled (see Command.SetCaptureTerminal).
+func (cp *capture) wrapStderr(w io.Writer) io.Writer {
+	if !cp.terminal && isTerminal(w) {
+		return w
+	}
+
+	return cp.stderr.wrap(w)
+}
+
+// isTerminal returns true if "w" is a terminal. Passing it to the command as is lets the command detect it (e.g. to
+// print colors or progress).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isatty.IsTerminal(f.Fd())
+}
+
+// attach attaches the captured output to the error. Standard error captured by exec.Cmd (i.e. when calling
+// exec.Cmd.Output with a nil exec.Cmd.Stderr) takes precedence.
+func (cp *capture) attach(e *ExecutionError) *ExecutionError {
+	if e.capturedStdout == "" {
+		e.capturedStdout = cp.stdout.String()
+	}
+
+	if e.capturedStderr == "" {
+		e.capturedStderr = cp.stderr.String()
+	}
+
+	return e
+}
+
+// ringBuffer is an io.Writer that retains the last bytes written to it, up to a fixed size. It is safe for concurrent
+// use. A nil *ringBuffer discards all writes.
+type ringBuffer struct {
+	m    *sync.Mutex
+	buf  []byte
+	pos  int
+	full bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{
+		m:   &sync.Mutex{},
+		buf: make([]byte, size),
+	}
+}
+
+// Write implements the io.Writer interface.
+func (b *ringBuffer) Write(p []byte) (int, error) {
+	if b == nil {
+		return len(p), nil
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	n := len(p)
+
+	if len(p) >= len(b.buf) {
+		copy(b.buf, p[len(p)-len(b.buf):])
+		b.pos = 0
+		b.full = true
+		return n, nil
+	}
+
+	copied := copy(b.buf[b.pos:], p)
+	copy(b.buf, p[copied:])
+
+	if b.pos+len(p) >= len(b.buf) {
+		b.full = true
+	}
+
+	b.pos = (b.pos + len(p)) % len(b.buf)
+	return n, nil
+}
+
+// String returns the retained bytes.
+func (b *ringBuffer) String() string {
+	if b == nil {
+		return ""
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if !b.full {
+		return string(b.buf[:b.pos])
+	}
+
+	return string(b.buf[b.pos:]) + string(b.buf[:b.pos])
+}
+
+func (b *ringBuffer) wrap(w io.Writer) io.Writer {
+	switch {
+	case b == nil:
+		return w
+	case w == nil:
+		return b
+	default:
+		return io.MultiWriter(w, b)
+	}
+}
@@ -2,13 +2,19 @@ package shellz
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/ibrt/golang-lib/consolez"
 	"github.com/ibrt/golang-lib/errorz"
@@ -18,6 +24,18 @@ import (
 var (
 	// DefaultExecutor is the default Executor for commands.
 	DefaultExecutor Executor = &RealExecutor{}
+
+	// ErrTimeout is matched (using "errors.Is") by an *ExecutionError caused by the command timing out.
+	ErrTimeout = errors.New("timed out")
+)
+
+const (
+	// DefaultGracePeriod is the default time given to a canceled command to exit after SIGTERM, before SIGKILL.
+	DefaultGracePeriod = 10 * time.Second
+
+	// DefaultCaptureSize is the default maximum number of bytes of output retained by a command and attached to the
+	// *ExecutionError when it fails (see Command.SetCaptureSize).
+	DefaultCaptureSize = 16 * 1024
 )
 
 // RestoreDefaultExecutor restores the default executor.
@@ -30,9 +48,11 @@ type Executor interface {
 	ExecCmdCombinedOutput(c *Command, cmd *exec.Cmd) ([]byte, error)
 	ExecCmdOutput(c *Command, cmd *exec.Cmd) ([]byte, error)
 	ExecCmdRun(c *Command, cmd *exec.Cmd) error
+	ExecCmdSignal(c *Command, cmd *exec.Cmd, sig syscall.Signal) error
 	ExecCmdStart(c *Command, cmd *exec.Cmd) error
 	ExecCmdWait(c *Command, cmd *exec.Cmd) error
 	ExecLookPath(c *Command, file string) (string, error)
+	NetDialContext(ctx context.Context, c *Command, network, address string) (net.Conn, error)
 	OSChdir(c *Command, dir string) error
 	SyscallExec(c *Command, argv0 string, argv []string, envv []string) error
 }
@@ -57,6 +77,32 @@ func (e *RealExecutor) ExecCmdRun(_ *Command, cmd *exec.Cmd) error {
 	return cmd.Run()
 }
 
+// ExecCmdSignal implements the Executor interface.
+// If the command runs in its own process group (i.e. it was started using Command.Start), the whole group is signaled.
+// It returns an error matching "os.ErrProcessDone" if the process is not running, or it has already been waited for,
+// as its process group ID may have been reused.
+func (e *RealExecutor) ExecCmdSignal(_ *Command, cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return errorz.Wrap(os.ErrProcessDone)
+	}
+
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		if err := cmd.Process.Signal(syscall.Signal(0)); errors.Is(err, os.ErrProcessDone) {
+			return errorz.Wrap(os.ErrProcessDone)
+		}
+
+		if err := syscall.Kill(-cmd.Process.Pid, sig); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				return errorz.Wrap(os.ErrProcessDone)
+			}
+			return errorz.Wrap(err)
+		}
+		return nil
+	}
+
+	return errorz.MaybeWrap(cmd.Process.Signal(sig))
+}
+
 // ExecCmdStart implements the Executor interface.
 func (e *RealExecutor) ExecCmdStart(_ *Command, cmd *exec.Cmd) error {
 	return cmd.Start()
@@ -72,6 +118,11 @@ func (e *RealExecutor) ExecLookPath(_ *Command, file string) (string, error) {
 	return exec.LookPath(file)
 }
 
+// NetDialContext implements the Executor interface.
+func (e *RealExecutor) NetDialContext(ctx context.Context, _ *Command, network, address string) (net.Conn, error) {
+	return (&net.Dialer{}).DialContext(ctx, network, address)
+}
+
 // OSChdir implements the Executor interface.
 func (e *RealExecutor) OSChdir(_ *Command, dir string) error {
 	return os.Chdir(dir)
@@ -82,9 +133,25 @@ func (e *RealExecutor) SyscallExec(_ *Command, argv0 string, argv []string, envv
 	return syscall.Exec(argv0, argv, envv)
 }
 
+// DupFile duplicates the file descriptor, making sure that the duplicate is not inherited by child processes. It is
+// useful to Executor implementations which take ownership of *os.File writers or readers of an *exec.Cmd (e.g. pipes
+// created by "exec.Cmd.StdoutPipe"), which the caller may close as soon as the command starts.
+func DupFile(f *os.File) *os.File {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+
+	fd, err := syscall.Dup(int(f.Fd()))
+	errorz.MaybeMustWrap(err)
+	syscall.CloseOnExec(fd)
+
+	return os.NewFile(uintptr(fd), f.Name())
+}
+
 var (
 	_ error               = (*ExecutionError)(nil)
 	_ errorz.UnwrapSingle = (*ExecutionError)(nil)
+	_ errorz.IsHelper     = (*ExecutionError)(nil)
+	_ errorz.Redactor     = (*ExecutionError)(nil)
 )
 
 // ExecutionError describes an error.
@@ -94,28 +161,48 @@ type ExecutionError struct {
 	dir            string
 	env            map[string]string
 	exitCode       int
+	capturedStdout string
 	capturedStderr string
+	pipelineStage  int
+	pipelineLength int
+	ctxErr         error
 	err            error
 }
 
 // NewExecutionError initializes a new execution error.
 func NewExecutionError(err error, c *Command) *ExecutionError {
+	return newExecutionError(nil, err, c)
+}
+
+func newExecutionError(ctx context.Context, err error, c *Command) *ExecutionError {
 	e := &ExecutionError{
 		cmd:            c.cmd,
 		params:         memz.ShallowCopySlice(c.params),
 		dir:            c.dir,
 		env:            memz.ShallowCopyMap(c.env),
 		exitCode:       -1,
+		capturedStdout: "",
 		capturedStderr: "",
+		pipelineStage:  -1,
 		err:            err,
 	}
 
+	if ctx != nil {
+		e.ctxErr = ctx.Err()
+	}
+
 	if eErr, ok := errorz.As[*exec.ExitError](err); ok {
 		e.exitCode = eErr.ExitCode()
 
 		if len(eErr.Stderr) > 0 {
 			e.capturedStderr = string(eErr.Stderr)
 		}
+	} else if sErr, ok := errorz.As[*SimulatedExitError](err); ok {
+		e.exitCode = sErr.ExitCode()
+		e.capturedStderr = string(sErr.GetStderr())
+	} else if sErr, ok := errorz.As[*sshExitError](err); ok {
+		e.exitCode = sErr.ExitCode()
+		e.capturedStderr = string(sErr.stderr)
 	}
 
 	return e
@@ -146,14 +233,117 @@ func (e *ExecutionError) GetExitCode() int {
 	return e.exitCode
 }
 
+// GetCapturedStdout returns the tail of the standard output of the command, if captured (see Command.SetCaptureStdout).
+func (e *ExecutionError) GetCapturedStdout() string {
+	return e.capturedStdout
+}
+
 // GetCapturedStderr returns the originating captured standard error (if available).
 func (e *ExecutionError) GetCapturedStderr() string {
 	return e.capturedStderr
 }
 
-// Error implements the error interface.
+// GetPipelineStage returns the zero-based index of the failed stage if the error originates from a Pipeline, or -1.
+func (e *ExecutionError) GetPipelineStage() int {
+	return e.pipelineStage
+}
+
+// GetPipelineLength returns the number of stages if the error originates from a Pipeline, or 0.
+func (e *ExecutionError) GetPipelineLength() int {
+	return e.pipelineLength
+}
+
+// IsTimeout returns true if the command was terminated because its timeout (or context deadline) expired.
+func (e *ExecutionError) IsTimeout() bool {
+	return errors.Is(e.ctxErr, context.DeadlineExceeded)
+}
+
+// IsCanceled returns true if the command was terminated because its context was canceled.
+func (e *ExecutionError) IsCanceled() bool {
+	return errors.Is(e.ctxErr, context.Canceled)
+}
+
+// Error implements the error interface. The message includes the command, rendered as a shell command line (see
+// Command.String). Values of sensitive env vars and params (see errorz.IsRedactedField) are redacted from the message.
 func (e *ExecutionError) Error() string {
-	return "execution error: " + e.err.Error()
+	prefix := "execution error: "
+
+	if e.pipelineStage >= 0 {
+		prefix += fmt.Sprintf("pipeline stage %v of %v: ", e.pipelineStage+1, e.pipelineLength)
+	}
+
+	prefix += renderCommand(e.dir, errorz.Redact(e.env), e.cmd, memz.TransformSlice(e.params, e.redact)) + ": "
+
+	switch {
+	case e.IsTimeout():
+		return prefix + "timed out: " + e.redact(e.err.Error())
+	case e.IsCanceled():
+		return prefix + "canceled: " + e.redact(e.err.Error())
+	default:
+		return prefix + e.redact(e.err.Error())
+	}
+}
+
+// Is provides interoperability with "errors.Is". An *ExecutionError caused by a timeout matches ErrTimeout, and an
+// *ExecutionError caused by the context matches the context error.
+func (e *ExecutionError) Is(target error) bool {
+	return (target == ErrTimeout && e.IsTimeout()) || (e.ctxErr != nil && errors.Is(e.ctxErr, target))
+}
+
+// Redact implements the errorz.Redactor interface.
+// It returns a copy in which sensitive values are redacted from params, env, captured standard error, and message.
+func (e *ExecutionError) Redact() any {
+	params := make([]string, 0, len(e.params))
+
+	for _, p := range e.params {
+		params = append(params, e.redact(p))
+	}
+
+	return &ExecutionError{
+		cmd:            e.cmd,
+		params:         params,
+		dir:            e.dir,
+		env:            errorz.Redact(e.env),
+		exitCode:       e.exitCode,
+		capturedStdout: e.redact(e.capturedStdout),
+		capturedStderr: e.redact(e.capturedStderr),
+		pipelineStage:  e.pipelineStage,
+		pipelineLength: e.pipelineLength,
+		ctxErr:         e.ctxErr,
+		err:            errors.New(e.redact(e.err.Error())),
+	}
+}
+
+func (e *ExecutionError) redact(s string) string {
+	secrets := make([]string, 0)
+
+	for k, v := range e.env {
+		if errorz.IsRedactedField(k) {
+			secrets = append(secrets, v)
+		}
+	}
+
+	for i, p := range e.params {
+		if !strings.HasPrefix(p, "-") {
+			continue
+		}
+
+		if k, v, ok := strings.Cut(strings.TrimLeft(p, "-"), "="); ok {
+			if errorz.IsRedactedField(k) {
+				secrets = append(secrets, v)
+			}
+		} else if i+1 < len(e.params) && errorz.IsRedactedField(k) {
+			secrets = append(secrets, e.params[i+1])
+		}
+	}
+
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, errorz.RedactedValue)
+		}
+	}
+
+	return s
 }
 
 // Unwrap implements the errorz.UnwrapSingle interface.
@@ -161,25 +351,94 @@ func (e *ExecutionError) Unwrap() error {
 	return e.err
 }
 
+var (
+	_ error = (*SimulatedExitError)(nil)
+)
+
+// SimulatedExitError can be returned by an Executor in place of *exec.ExitError, to simulate a command exiting
+// unsuccessfully without running it (e.g. in tests). Like *exec.ExitError, it provides the exit code and captured
+// standard error to the resulting *ExecutionError.
+type SimulatedExitError struct {
+	message  string
+	exitCode int
+	stderr   []byte
+}
+
+// NewSimulatedExitError initializes a new *SimulatedExitError. If the message is empty, it defaults to the one of
+// *exec.ExitError, i.e. "exit status <exitCode>".
+func NewSimulatedExitError(message string, exitCode int, stderr []byte) *SimulatedExitError {
+	if message == "" {
+		message = fmt.Sprintf("exit status %v", exitCode)
+	}
+
+	return &SimulatedExitError{
+		message:  message,
+		exitCode: exitCode,
+		stderr:   memz.ShallowCopySlice(stderr),
+	}
+}
+
+// Error implements the error interface.
+func (e *SimulatedExitError) Error() string {
+	return e.message
+}
+
+// ExitCode returns the simulated exit code.
+func (e *SimulatedExitError) ExitCode() int {
+	return e.exitCode
+}
+
+// GetStderr returns the simulated captured standard error.
+func (e *SimulatedExitError) GetStderr() []byte {
+	return e.stderr
+}
+
+// EnvMode describes how the environment of a command is initialized, before applying the env of the command (see
+// Command.SetEnvMode).
+type EnvMode int
+
+// Known env modes.
+const (
+	// EnvModeInherit inherits the whole environment of the current process.
+	EnvModeInherit EnvMode = iota
+
+	// EnvModeClean does not inherit anything: the command only receives its own env.
+	EnvModeClean
+
+	// EnvModeAllowlist only inherits the variables in the allowlist (see Command.AddEnvAllowlist).
+	EnvModeAllowlist
+)
+
 // Command describes a command to be spawned in a shell.
 type Command struct {
 	cmd    string
 	params []string
 
-	dir      string
-	env      map[string]string
-	in       io.Reader
-	echo     *bool
-	executor Executor
+	dir         string
+	env         map[string]string
+	envMode     EnvMode
+	envAllow    []string
+	envUnset    []string
+	in          io.Reader
+	echo        *bool
+	ctx         context.Context
+	timeout     time.Duration
+	gracePeriod time.Duration
+	captureSize int
+	captureOut  bool
+	captureTTY  bool
+	executor    Executor
 }
 
 // NewCommand creates a new Command.
 func NewCommand(cmd string, params ...string) *Command {
 	return &Command{
-		cmd:      cmd,
-		params:   memz.ShallowCopySlice(params),
-		env:      make(map[string]string),
-		executor: DefaultExecutor,
+		cmd:         cmd,
+		params:      memz.ShallowCopySlice(params),
+		env:         make(map[string]string),
+		gracePeriod: DefaultGracePeriod,
+		captureSize: DefaultCaptureSize,
+		executor:    DefaultExecutor,
 	}
 }
 
@@ -216,17 +475,22 @@ func (c *Command) GetDir() string {
 	return c.dir
 }
 
-// SetEnv sets an environment variable on the command.
+// SetEnv sets an environment variable on the command. It overrides a previous UnsetEnv for the same key.
 func (c *Command) SetEnv(k, v string) *Command {
 	cc := c.clone()
 	cc.env[k] = v
+	cc.envUnset = slices.DeleteFunc(cc.envUnset, func(u string) bool { return u == k })
 	return cc
 }
 
-// MergeEnv sets all the environment variables on the command.
+// MergeEnv sets all the environment variables on the command. It overrides a previous UnsetEnv for the same keys.
 func (c *Command) MergeEnv(env map[string]string) *Command {
 	cc := c.clone()
 	cc.env = memz.MergeMaps(cc.env, env)
+	cc.envUnset = slices.DeleteFunc(cc.envUnset, func(u string) bool {
+		_, ok := env[u]
+		return ok
+	})
 	return cc
 }
 
@@ -235,6 +499,79 @@ func (c *Command) GetEnv() map[string]string {
 	return memz.ShallowCopyMap(c.env)
 }
 
+// UnsetEnv removes the given environment variables from the command, whether set on the command (see SetEnv) or
+// inherited from the current process.
+func (c *Command) UnsetEnv(keys ...string) *Command {
+	cc := c.clone()
+	for _, k := range keys {
+		delete(cc.env, k)
+	}
+	cc.envUnset = sortedUnique(append(cc.envUnset, keys...))
+	return cc
+}
+
+// GetUnsetEnv returns the keys of the environment variables removed from the command, sorted.
+func (c *Command) GetUnsetEnv() []string {
+	return memz.ShallowCopySlice(c.envUnset)
+}
+
+// SetEnvMode configures how the environment of the command is initialized, before applying its env (see SetEnv) and
+// removing the unset variables (see UnsetEnv). Defaults to EnvModeInherit.
+func (c *Command) SetEnvMode(envMode EnvMode) *Command {
+	cc := c.clone()
+	cc.envMode = envMode
+	return cc
+}
+
+// GetEnvMode returns the current env mode.
+func (c *Command) GetEnvMode() EnvMode {
+	return c.envMode
+}
+
+// AddEnvAllowlist adds the given keys to the variables inherited from the current process in EnvModeAllowlist.
+func (c *Command) AddEnvAllowlist(keys ...string) *Command {
+	cc := c.clone()
+	cc.envAllow = sortedUnique(append(cc.envAllow, keys...))
+	return cc
+}
+
+// GetEnvAllowlist returns the keys of the variables inherited from the current process in EnvModeAllowlist, sorted.
+func (c *Command) GetEnvAllowlist() []string {
+	return memz.ShallowCopySlice(c.envAllow)
+}
+
+// GetEnviron returns the environment the command runs with, as a list of "key=value" strings sorted by key. Each key
+// appears at most once: the env of the command takes precedence over the inherited variables.
+func (c *Command) GetEnviron() []string {
+	env := make(map[string]string)
+
+	if c.envMode != EnvModeClean {
+		for _, kv := range os.Environ() {
+			k, v, _ := strings.Cut(kv, "=")
+
+			if c.envMode == EnvModeInherit || slices.Contains(c.envAllow, k) {
+				env[k] = v
+			}
+		}
+	}
+
+	for _, k := range c.envUnset {
+		delete(env, k)
+	}
+
+	for k, v := range c.env {
+		env[k] = v
+	}
+
+	environ := make([]string, 0, len(env))
+
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		environ = append(environ, fmt.Sprintf("%v=%v", k, env[k]))
+	}
+
+	return environ
+}
+
 // SetIn sets the input to the command.
 func (c *Command) SetIn(in io.Reader) *Command {
 	cc := c.clone()
@@ -262,6 +599,91 @@ func (c *Command) GetEcho() *bool {
 	return memz.Ptr(*c.echo)
 }
 
+// SetContext sets a context on the command. When the context is done, the command is terminated: it receives SIGTERM,
+// followed by SIGKILL after the grace period (see SetGracePeriod). The command stays in the process group of the
+// caller, so it keeps receiving the signals sent by the terminal (e.g. on Ctrl-C), but its children are not signaled.
+func (c *Command) SetContext(ctx context.Context) *Command {
+	cc := c.clone()
+	cc.ctx = ctx
+	return cc
+}
+
+// GetContext returns the current context (nil if not set).
+func (c *Command) GetContext() context.Context {
+	return c.ctx
+}
+
+// SetTimeout sets a timeout on the command. When the timeout expires, the command is terminated as if its context
+// was done (see SetContext), and the resulting *ExecutionError matches ErrTimeout. A zero value means no timeout.
+func (c *Command) SetTimeout(timeout time.Duration) *Command {
+	cc := c.clone()
+	cc.timeout = timeout
+	return cc
+}
+
+// GetTimeout returns the current timeout (zero if not set).
+func (c *Command) GetTimeout() time.Duration {
+	return c.timeout
+}
+
+// SetGracePeriod sets the time given to the command to exit after SIGTERM, before SIGKILL, when it is terminated
+// because its context is done or its timeout expires. It also bounds the time spent waiting for the output of the
+// command after it exits, e.g. if a background child keeps it open (see exec.Cmd.WaitDelay). Defaults to
+// DefaultGracePeriod.
+func (c *Command) SetGracePeriod(gracePeriod time.Duration) *Command {
+	cc := c.clone()
+	cc.gracePeriod = gracePeriod
+	return cc
+}
+
+// GetGracePeriod returns the current grace period.
+func (c *Command) GetGracePeriod() time.Duration {
+	return c.gracePeriod
+}
+
+// SetCaptureSize sets the maximum number of bytes of output retained while the command runs, in any mode. When the
+// command fails, the last bytes of its standard error (and optionally output, see SetCaptureStdout) are attached to the
+// *ExecutionError (see ExecutionError.GetCapturedStderr). Output written to a terminal is only captured if enabled
+// (see SetCaptureTerminal). A zero value disables capturing. Defaults to DefaultCaptureSize.
+func (c *Command) SetCaptureSize(captureSize int) *Command {
+	cc := c.clone()
+	cc.captureSize = captureSize
+	return cc
+}
+
+// GetCaptureSize returns the current capture size.
+func (c *Command) GetCaptureSize() int {
+	return c.captureSize
+}
+
+// SetCaptureStdout configures whether the standard output is captured in addition to the standard error (see
+// SetCaptureSize). Defaults to false.
+func (c *Command) SetCaptureStdout(captureStdout bool) *Command {
+	cc := c.clone()
+	cc.captureOut = captureStdout
+	return cc
+}
+
+// GetCaptureStdout returns the current standard output capture configuration.
+func (c *Command) GetCaptureStdout() bool {
+	return c.captureOut
+}
+
+// SetCaptureTerminal configures whether output written to a terminal (e.g. os.Stderr in Run, when attached to one) is
+// captured too (see SetCaptureSize). Capturing it requires connecting the command to a pipe instead, so that it can no
+// longer detect the terminal (e.g. to print colors or progress). Output written elsewhere is always captured.
+// Defaults to false.
+func (c *Command) SetCaptureTerminal(captureTerminal bool) *Command {
+	cc := c.clone()
+	cc.captureTTY = captureTerminal
+	return cc
+}
+
+// GetCaptureTerminal returns the current terminal capture configuration.
+func (c *Command) GetCaptureTerminal() bool {
+	return c.captureTTY
+}
+
 // SetExecutor sets the Executor for the command.
 func (c *Command) SetExecutor(executor Executor) *Command {
 	cc := c.clone()
@@ -272,12 +694,15 @@ func (c *Command) SetExecutor(executor Executor) *Command {
 // Run runs the command.
 func (c *Command) Run() error {
 	c.maybeEcho(true)
-	cmd := c.newCmd()
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd, ctx, done := c.newCmd()
+	defer done()
+
+	cp := c.newCapture()
+	cmd.Stdout = cp.wrapStdout(os.Stdout)
+	cmd.Stderr = cp.wrapStderr(os.Stderr)
 
 	if err := c.executor.ExecCmdRun(c, cmd); err != nil {
-		return NewExecutionError(err, c)
+		return cp.attach(newExecutionError(ctx, err, c))
 	}
 
 	return nil
@@ -292,17 +717,21 @@ func (c *Command) MustRun() {
 // Standard error is not redirected.
 func (c *Command) Output(echoStderr bool) ([]byte, error) {
 	c.maybeEcho(false)
-	cmd := c.newCmd()
+	cmd, ctx, done := c.newCmd()
+	defer done()
+
+	cp := c.newCapture()
 
 	if echoStderr {
-		cmd.Stderr = os.Stderr
+		cmd.Stderr = cp.wrapStderr(os.Stderr)
 	} else {
 		cmd.Stderr = nil
 	}
 
 	out, err := c.executor.ExecCmdOutput(c, cmd)
 	if err != nil {
-		return nil, NewExecutionError(err, c)
+		_, _ = cp.stdout.Write(out)
+		return nil, cp.attach(newExecutionError(ctx, err, c))
 	}
 
 	return out, nil
@@ -333,12 +762,17 @@ func (c *Command) MustOutputString(echoStderr bool) string {
 }
 
 // CombinedOutput runs the command and returns a buffer containing the resulting combined standard output and error.
+// As the streams are merged, the standard error captured on failure is the tail of the combined output.
 func (c *Command) CombinedOutput() ([]byte, error) {
 	c.maybeEcho(false)
+	cmd, ctx, done := c.newCmd()
+	defer done()
 
-	out, err := c.executor.ExecCmdCombinedOutput(c, c.newCmd())
+	out, err := c.executor.ExecCmdCombinedOutput(c, cmd)
 	if err != nil {
-		return nil, NewExecutionError(err, c)
+		cp := c.newCapture()
+		_, _ = cp.stderr.Write(out)
+		return nil, cp.attach(newExecutionError(ctx, err, c))
 	}
 
 	return out, nil
@@ -368,44 +802,15 @@ func (c *Command) MustCombinedOutputString() string {
 	return buf
 }
 
-// Lines runs the command and calls "lineFunc" with each line of output.
+// Lines runs the command and calls "lineFunc" with each line of output. Standard output and error are merged, see
+// LineEvents for details and for a way to tell them apart.
 func (c *Command) Lines(lineFunc func(string)) error {
-	c.maybeEcho(true)
-	cmd := c.newCmd()
-
-	outR, err := cmd.StdoutPipe()
-	errorz.MaybeMustWrap(err)
-
-	errR, err := cmd.StderrPipe()
-	errorz.MaybeMustWrap(err)
-
-	m := &sync.Mutex{}
-	wg := &sync.WaitGroup{}
-	wg.Add(2)
-
-	callLineFunc := func(line string) {
-		m.Lock()
-		defer m.Unlock()
-		lineFunc(line)
-	}
-
-	go c.handleLines(wg, outR, callLineFunc)
-	go c.handleLines(wg, errR, callLineFunc)
-
-	if err := c.executor.ExecCmdStart(c, cmd); err != nil {
-		return NewExecutionError(err, c)
-	}
-
-	wg.Wait()
-
-	if err := c.executor.ExecCmdWait(c, cmd); err != nil {
-		return NewExecutionError(err, c)
-	}
-
-	return nil
+	return c.LineEvents(func(e *LineEvent) {
+		lineFunc(e.Text)
+	})
 }
 
-func (c *Command) handleLines(wg *sync.WaitGroup, r io.Reader, lineFunc func(string)) {
+func handleLines(wg *sync.WaitGroup, r io.Reader, lineFunc func(string)) {
 	defer wg.Done()
 	defer func() { recover() }()
 
@@ -451,7 +856,7 @@ func (c *Command) Exec() error {
 		}
 	}
 
-	if err := c.executor.SyscallExec(c, binFilePath, append([]string{c.cmd}, c.params...), c.newEnviron()); err != nil {
+	if err := c.executor.SyscallExec(c, binFilePath, append([]string{c.cmd}, c.params...), c.GetEnviron()); err != nil {
 		return NewExecutionError(err, c)
 	}
 
@@ -468,36 +873,97 @@ func (c *Command) maybeEcho(defaultEcho bool) {
 		return
 	}
 
-	consolez.DefaultCLI.Command(c.cmd, c.params...)
-}
+	prefix, words := renderCommandParts(c.dir, c.env, c.cmd, c.params)
+	consolez.DefaultCLI.QuotedCommand(prefix, words[0], words[1:]...)
+}
+
+// newCmd initializes a new *exec.Cmd. If the command has a context or timeout, it also returns the derived context,
+// and configures the *exec.Cmd to terminate the process when the context is done. The returned function must
+// be called once the *exec.Cmd has completed.
+func (c *Command) newCmd() (*exec.Cmd, context.Context, func()) {
+	if c.ctx == nil && c.timeout <= 0 {
+		cmd := exec.Command(c.cmd, c.params...)
+		cmd.Dir = c.dir
+		cmd.Env = c.GetEnviron()
+		cmd.Stdin = c.in
+		cmd.WaitDelay = c.gracePeriod
+		return cmd, nil, func() {}
+	}
+
+	ctx, cancel := c.newContext()
+	doneC := make(chan struct{})
 
-func (c *Command) newCmd() *exec.Cmd {
-	cmd := exec.Command(c.cmd, c.params...)
+	cmd := exec.CommandContext(ctx, c.cmd, c.params...)
 	cmd.Dir = c.dir
-	cmd.Env = c.newEnviron()
+	cmd.Env = c.GetEnviron()
 	cmd.Stdin = c.in
-	return cmd
+	cmd.WaitDelay = c.gracePeriod
+
+	cmd.Cancel = func() error {
+		err := c.executor.ExecCmdSignal(c, cmd, syscall.SIGTERM)
+
+		if err == nil {
+			go func() {
+				t := time.NewTimer(c.gracePeriod)
+				defer t.Stop()
+
+				select {
+				case <-t.C:
+				case <-doneC:
+				}
+
+				_ = c.executor.ExecCmdSignal(c, cmd, syscall.SIGKILL)
+			}()
+		}
+
+		return err
+	}
+
+	return cmd, ctx, func() {
+		close(doneC)
+		cancel()
+	}
 }
 
-func (c *Command) newEnviron() []string {
-	env := os.Environ()
+// newContext derives a context from the context and timeout of the command. It must only be called if the command has
+// a context or timeout.
+func (c *Command) newContext() (context.Context, context.CancelFunc) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	for k, v := range c.env {
-		env = append(env, fmt.Sprintf("%v=%v", k, v))
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
 	}
 
-	return env
+	return ctx, func() {}
+}
+
+func sortedUnique(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return slices.Compact(values)
 }
 
 func (c *Command) clone() *Command {
 	cc := &Command{
-		cmd:      c.cmd,
-		params:   memz.ShallowCopySlice(c.params),
-		dir:      c.dir,
-		env:      memz.ShallowCopyMap(c.env),
-		in:       c.in,
-		echo:     nil,
-		executor: c.executor,
+		cmd:         c.cmd,
+		params:      memz.ShallowCopySlice(c.params),
+		dir:         c.dir,
+		env:         memz.ShallowCopyMap(c.env),
+		envMode:     c.envMode,
+		envAllow:    memz.ShallowCopySlice(c.envAllow),
+		envUnset:    memz.ShallowCopySlice(c.envUnset),
+		in:          c.in,
+		echo:        nil,
+		ctx:         c.ctx,
+		timeout:     c.timeout,
+		gracePeriod: c.gracePeriod,
+		captureSize: c.captureSize,
+		captureOut:  c.captureOut,
+		captureTTY:  c.captureTTY,
+		executor:    c.executor,
 	}
 
 	if c.echo != nil {
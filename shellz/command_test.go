@@ -1,22 +1,31 @@
 package shellz_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	"go.uber.org/mock/gomock"
 
 	"github.com/ibrt/golang-lib/consolez"
 	"github.com/ibrt/golang-lib/errorz"
 	"github.com/ibrt/golang-lib/filez"
 	"github.com/ibrt/golang-lib/fixturez"
 	"github.com/ibrt/golang-lib/shellz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
 )
 
 type CommandSuite struct {
@@ -53,7 +62,8 @@ func (*CommandSuite) TestRun_Error(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("cat: cae0e988-f55b-4803-a471-a877b686d1a8: No such file or directory\n"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	xErr, ok := errorz.As[*exec.ExitError](err)
 	g.Expect(ok).To(BeTrue())
@@ -64,6 +74,33 @@ func (*CommandSuite) TestRun_Error(g *WithT) {
 	g.Expect(errBuf).To(Equal("cat: cae0e988-f55b-4803-a471-a877b686d1a8: No such file or directory\n"))
 }
 
+func (*CommandSuite) TestRun_Terminal(g *WithT, ctrl *gomock.Controller) {
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	g.Expect(err).To(Succeed())
+	defer func() { _ = tty.Close() }()
+
+	stderr := os.Stderr
+	os.Stderr = tty
+	defer func() { os.Stderr = stderr }()
+
+	m := tshellz.NewMockExecutor(ctrl)
+
+	m.EXPECT().ExecCmdRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *shellz.Command, cmd *exec.Cmd) error {
+		g.Expect(cmd.Stderr).To(BeIdenticalTo(tty))
+		g.Expect(cmd.WaitDelay).To(Equal(shellz.DefaultGracePeriod))
+		return nil
+	})
+
+	g.Expect(shellz.NewCommand("cmd").SetEcho(false).SetExecutor(m).Run()).To(Succeed())
+
+	m.EXPECT().ExecCmdRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *shellz.Command, cmd *exec.Cmd) error {
+		g.Expect(cmd.Stderr).ToNot(BeIdenticalTo(tty))
+		return nil
+	})
+
+	g.Expect(shellz.NewCommand("cmd").SetEcho(false).SetExecutor(m).SetCaptureTerminal(true).Run()).To(Succeed())
+}
+
 func (*CommandSuite) TestMustRun_Success(g *WithT) {
 	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
 	defer fixturez.ResetOutputCapture()
@@ -119,8 +156,8 @@ func (*CommandSuite) TestOutput_Error_EchoStderrTrue(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.GetCapturedStderr()).To(BeEmpty())
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("cat: cae0e988-f55b-4803-a471-a877b686d1a8: No such file or directory\n"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	outBuf, errBuf := fixturez.MustEndOutputCapture()
 	g.Expect(outBuf).To(BeEmpty())
@@ -143,7 +180,7 @@ func (*CommandSuite) TestOutput_Error_EchoStderrFalse(g *WithT) {
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
 	g.Expect(eErr.GetCapturedStderr()).To(Equal("cat: cae0e988-f55b-4803-a471-a877b686d1a8: No such file or directory\n"))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	outBuf, errBuf := fixturez.MustEndOutputCapture()
 	g.Expect(outBuf).To(BeEmpty())
@@ -207,7 +244,7 @@ func (*CommandSuite) TestOutputString_Error(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	outBuf, errBuf := fixturez.MustEndOutputCapture()
 	g.Expect(outBuf).To(BeEmpty())
@@ -286,7 +323,8 @@ func (*CommandSuite) TestCombinedOutput_Error(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("cat: cae0e988-f55b-4803-a471-a877b686d1a8: No such file or directory\n"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	outBuf, errBuf := fixturez.MustEndOutputCapture()
 	g.Expect(outBuf).To(BeEmpty())
@@ -337,7 +375,7 @@ func (*CommandSuite) TestCombinedOutputString_Error(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	outBuf, errBuf := fixturez.MustEndOutputCapture()
 	g.Expect(outBuf).To(BeEmpty())
@@ -452,7 +490,7 @@ func (*CommandSuite) TestLines_Error(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cat cae0e988-f55b-4803-a471-a877b686d1a8: exit status 1"))
 
 	g.Expect(receivedLines).To(Equal([]string{
 		"cat: cae0e988-f55b-4803-a471-a877b686d1a8: No such file or directory",
@@ -484,7 +522,7 @@ func (*CommandSuite) TestLines_Error_Start(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(-1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exec: \"cae0e988-f55b-4803-a471-a877b686d1a8\": executable file not found in $PATH"))
+	g.Expect(eErr.Error()).To(Equal("execution error: cae0e988-f55b-4803-a471-a877b686d1a8: exec: \"cae0e988-f55b-4803-a471-a877b686d1a8\": executable file not found in $PATH"))
 
 	g.Expect(receivedLines).To(Equal([]string{}))
 
@@ -515,7 +553,7 @@ func (*CommandSuite) TestLines_Error_LongLine(g *WithT) {
 	g.Expect(eErr.GetDir()).To(BeEmpty())
 	g.Expect(eErr.GetEnv()).To(BeEmpty())
 	g.Expect(eErr.GetExitCode()).To(Equal(1))
-	g.Expect(eErr.Error()).To(Equal("execution error: exit status 1"))
+	g.Expect(eErr.Error()).To(Equal(fmt.Sprintf("execution error: cat %v: exit status 1", longLine)))
 
 	g.Expect(receivedLines).To(Equal([]string{
 		fmt.Sprintf("cat: %v: File name too long", longLine),
@@ -583,6 +621,113 @@ func (*CommandSuite) TestMustLines_Error(g *WithT) {
 	g.Expect(errBuf).To(BeEmpty())
 }
 
+func (*CommandSuite) TestTimeout_Success(g *WithT) {
+	out, err := shellz.NewCommand("echo", "ok").SetTimeout(time.Minute).CombinedOutputString()
+	g.Expect(err).To(Succeed())
+	g.Expect(out).To(Equal("ok\n"))
+
+	out, err = shellz.NewCommand("echo", "ok").SetContext(context.Background()).CombinedOutputString()
+	g.Expect(err).To(Succeed())
+	g.Expect(out).To(Equal("ok\n"))
+}
+
+func (*CommandSuite) TestTimeout_Error(g *WithT) {
+	startTime := time.Now()
+
+	_, err := shellz.NewCommand("sleep", "10").SetTimeout(100 * time.Millisecond).CombinedOutput()
+	g.Expect(time.Since(startTime)).To(BeNumerically("<", 5*time.Second))
+	g.Expect(err).To(MatchError("execution error: sleep 10: timed out: signal: terminated"))
+	g.Expect(errors.Is(err, shellz.ErrTimeout)).To(BeTrue())
+	g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	g.Expect(errors.Is(err, context.Canceled)).To(BeFalse())
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.IsTimeout()).To(BeTrue())
+	g.Expect(eErr.IsCanceled()).To(BeFalse())
+	g.Expect(eErr.GetExitCode()).To(Equal(-1))
+}
+
+func (*CommandSuite) TestTimeout_GracePeriod(g *WithT) {
+	startTime := time.Now()
+
+	err := shellz.NewCommand("sh", "-c", `trap "" TERM; exec sleep 10`).
+		SetEcho(false).
+		SetTimeout(100 * time.Millisecond).
+		SetGracePeriod(200 * time.Millisecond).
+		Run()
+	g.Expect(time.Since(startTime)).To(BeNumerically(">=", 300*time.Millisecond))
+	g.Expect(time.Since(startTime)).To(BeNumerically("<", 5*time.Second))
+	g.Expect(err).To(MatchError(`execution error: sh -c 'trap "" TERM; exec sleep 10': timed out: signal: killed`))
+	g.Expect(errors.Is(err, shellz.ErrTimeout)).To(BeTrue())
+}
+
+func (*CommandSuite) TestTimeout_Children(g *WithT) {
+	pid := ""
+	start := time.Now()
+
+	err := shellz.NewCommand("sh", "-c", `sleep 10 & echo $!; wait`).
+		SetEcho(false).
+		SetTimeout(500 * time.Millisecond).
+		SetGracePeriod(500 * time.Millisecond).
+		Lines(func(line string) { pid = line })
+	g.Expect(err).To(MatchError("execution error: sh -c 'sleep 10 & echo $!; wait': timed out: signal: terminated"))
+	g.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	g.Expect(pid).ToNot(BeEmpty())
+
+	iPID, err := strconv.Atoi(pid)
+	g.Expect(err).To(Succeed())
+	defer func() { _ = syscall.Kill(iPID, syscall.SIGKILL) }()
+
+	pgid, err := syscall.Getpgid(iPID)
+	g.Expect(err).To(Succeed())
+	g.Expect(pgid).To(Equal(syscall.Getpgrp()))
+}
+
+func (*CommandSuite) TestContext_Canceled(g *WithT) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err := shellz.NewCommand("sleep", "10").SetContext(ctx).Output(false)
+	g.Expect(err).To(MatchError("execution error: sleep 10: canceled: signal: terminated"))
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	g.Expect(errors.Is(err, shellz.ErrTimeout)).To(BeFalse())
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.IsTimeout()).To(BeFalse())
+	g.Expect(eErr.IsCanceled()).To(BeTrue())
+
+	_, err = shellz.NewCommand("sleep", "10").SetContext(ctx).Output(false)
+	g.Expect(err).To(MatchError("execution error: sleep 10: canceled: context canceled"))
+}
+
+func (*CommandSuite) TestSetContext(g *WithT) {
+	ctx := context.WithValue(context.Background(), struct{}{}, "v")
+
+	cmd := shellz.NewCommand("cmd")
+	g.Expect(cmd.GetContext()).To(BeNil())
+	g.Expect(cmd.GetTimeout()).To(BeZero())
+	g.Expect(cmd.GetGracePeriod()).To(Equal(shellz.DefaultGracePeriod))
+
+	cmd = cmd.SetContext(ctx).SetTimeout(time.Second).SetGracePeriod(time.Minute).AddParams("p")
+	g.Expect(cmd.GetContext()).To(Equal(ctx))
+	g.Expect(cmd.GetTimeout()).To(Equal(time.Second))
+	g.Expect(cmd.GetGracePeriod()).To(Equal(time.Minute))
+}
+
+func (*CommandSuite) TestRealExecutor_ExecCmdSignal(g *WithT) {
+	err := (&shellz.RealExecutor{}).ExecCmdSignal(shellz.NewCommand("cmd"), exec.Command("cmd"), syscall.SIGTERM)
+	g.Expect(errors.Is(err, os.ErrProcessDone)).To(BeTrue())
+
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	g.Expect(cmd.Run()).To(Succeed())
+
+	err = (&shellz.RealExecutor{}).ExecCmdSignal(shellz.NewCommand("true"), cmd, syscall.SIGTERM)
+	g.Expect(errors.Is(err, os.ErrProcessDone)).To(BeTrue())
+}
+
 // TestExecExecutor is a mock shellz.Executor used by TestExec.
 type TestExecExecutor struct {
 	*shellz.RealExecutor
@@ -612,10 +757,10 @@ func (s *CommandSuite) TestExec_Success(g *WithT) {
 
 func (*CommandSuite) TestExec_PreparationErrors(g *WithT) {
 	g.Expect(shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8").Exec()).
-		To(MatchError(`execution error: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH`))
+		To(MatchError(`execution error: cae0e988-f55b-4803-a471-a877b686d1a8: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH`))
 
 	g.Expect(shellz.NewCommand("cat").SetDir("cae0e988-f55b-4803-a471-a877b686d1a8").Exec()).
-		To(MatchError(`execution error: chdir cae0e988-f55b-4803-a471-a877b686d1a8: no such file or directory`))
+		To(MatchError(`execution error: cd cae0e988-f55b-4803-a471-a877b686d1a8 && cat: chdir cae0e988-f55b-4803-a471-a877b686d1a8: no such file or directory`))
 }
 
 func (s *CommandSuite) TestExec_ExecutionError(g *WithT) {
@@ -628,7 +773,111 @@ func (s *CommandSuite) TestExec_ExecutionError(g *WithT) {
 			}).
 			SetEnv("K", "V").
 			Exec()).
-		To(MatchError("execution error: test error"))
+		To(MatchError("execution error: K=V ls .: test error"))
+}
+
+func (*CommandSuite) TestSimulatedExitError(g *WithT) {
+	err := shellz.NewSimulatedExitError("", 3, []byte("stderr"))
+	g.Expect(err).To(MatchError("exit status 3"))
+	g.Expect(err.ExitCode()).To(Equal(3))
+	g.Expect(err.GetStderr()).To(Equal([]byte("stderr")))
+
+	eErr := shellz.NewExecutionError(errorz.Wrap(err), shellz.NewCommand("cmd"))
+	g.Expect(eErr).To(MatchError("execution error: cmd: exit status 3"))
+	g.Expect(eErr.GetExitCode()).To(Equal(3))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("stderr"))
+
+	g.Expect(shellz.NewSimulatedExitError("signal: killed", -1, nil)).To(MatchError("signal: killed"))
+}
+
+func (*CommandSuite) TestDupFile(g *WithT) {
+	r, w, err := os.Pipe()
+	g.Expect(err).To(Succeed())
+	defer func() { _ = r.Close() }()
+
+	dw := shellz.DupFile(w)
+	g.Expect(dw.Name()).To(Equal(w.Name()))
+	g.Expect(dw.Fd()).ToNot(Equal(w.Fd()))
+	g.Expect(w.Close()).To(Succeed())
+
+	_, err = dw.WriteString("data")
+	g.Expect(err).To(Succeed())
+	g.Expect(dw.Close()).To(Succeed())
+
+	buf, err := io.ReadAll(r)
+	g.Expect(err).To(Succeed())
+	g.Expect(string(buf)).To(Equal("data"))
+}
+
+func (*CommandSuite) TestCapture(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	cmd := shellz.NewCommand("sh", "-c", "echo out1; echo err1 >&2; echo out2; echo err2 >&2; exit 3").SetEcho(false)
+
+	err := cmd.Run()
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(BeEmpty())
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err1\nerr2\n"))
+
+	err = cmd.SetCaptureSize(10).SetCaptureStdout(true).Run()
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(Equal("out1\nout2\n"))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err1\nerr2\n"))
+
+	err = cmd.SetCaptureSize(7).Run()
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(BeEmpty())
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("1\nerr2\n"))
+
+	err = cmd.SetCaptureSize(0).SetCaptureStdout(true).Run()
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(BeEmpty())
+	g.Expect(eErr.GetCapturedStderr()).To(BeEmpty())
+
+	_, err = cmd.SetCaptureStdout(true).Output(true)
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(Equal("out1\nout2\n"))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err1\nerr2\n"))
+
+	_, err = cmd.SetCaptureSize(12).CombinedOutput()
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(BeEmpty())
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("1\nout2\nerr2\n"))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal("out1\nout2\nout1\nout2\nout1\nout2\nout1\nout2\n"))
+	g.Expect(errBuf).To(Equal("err1\nerr2\nerr1\nerr2\nerr1\nerr2\nerr1\nerr2\nerr1\nerr2\n"))
+}
+
+func (*CommandSuite) TestExecutionError_Redact(g *WithT) {
+	err := shellz.NewCommand("f3c1f4c2-secret-value", "--password=p4ss", "--api-key", "k3y", "-v", "plain").
+		SetEnv("GITHUB_TOKEN", "t0ken").
+		SetEnv("OTHER", "plain").
+		Run()
+
+	g.Expect(err).To(MatchError(`execution error: GITHUB_TOKEN='[REDACTED]' OTHER=plain f3c1f4c2-secret-value '--password=[REDACTED]' --api-key '[REDACTED]' -v plain: exec: "f3c1f4c2-secret-value": executable file not found in $PATH`))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+
+	err = shellz.NewExecutionError(fmt.Errorf("failed with p4ss, k3y, t0ken, and plain"), shellz.NewCommand(
+		eErr.GetCommand(), eErr.GetParams()...).MergeEnv(eErr.GetEnv()))
+	g.Expect(err).To(MatchError("execution error: GITHUB_TOKEN='[REDACTED]' OTHER=plain f3c1f4c2-secret-value '--password=[REDACTED]' --api-key '[REDACTED]' -v plain: failed with [REDACTED], [REDACTED], [REDACTED], and plain"))
+
+	dump := errorz.SDump(errorz.Wrap(err))
+	g.Expect(dump).ToNot(ContainSubstring("p4ss"))
+	g.Expect(dump).ToNot(ContainSubstring("k3y"))
+	g.Expect(dump).ToNot(ContainSubstring("t0ken"))
+	g.Expect(dump).To(ContainSubstring(`(string) (len=21) "--password=[REDACTED]"`))
+	g.Expect(dump).To(ContainSubstring(`(string) (len=12) "GITHUB_TOKEN": (string) (len=10) "[REDACTED]"`))
+	g.Expect(dump).To(ContainSubstring(`(string) (len=5) "OTHER": (string) (len=5) "plain"`))
 }
 
 func (*CommandSuite) TestMustExec_Error(g *WithT) {
@@ -636,7 +885,7 @@ func (*CommandSuite) TestMustExec_Error(g *WithT) {
 		func() {
 			shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8").MustExec()
 		}).
-		To(PanicWith(MatchError(`execution error: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH`)))
+		To(PanicWith(MatchError(`execution error: cae0e988-f55b-4803-a471-a877b686d1a8: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH`)))
 }
 
 func (*CommandSuite) TestAddParams(g *WithT) {
@@ -704,6 +953,60 @@ func (*CommandSuite) TestMergeEnv(g *WithT) {
 	g.Expect(errBuf).To(BeEmpty())
 }
 
+func (*CommandSuite) TestUnsetEnv(g *WithT) {
+	g.Expect(os.Setenv("SHELLZ_TEST_K1", "v1")).To(Succeed())
+	defer func() { _ = os.Unsetenv("SHELLZ_TEST_K1") }()
+
+	cmd := shellz.NewCommand("env").SetEcho(false).SetEnv("SHELLZ_TEST_K2", "v2")
+	g.Expect(cmd.MustOutputString(false)).To(ContainSubstring("SHELLZ_TEST_K1=v1"))
+
+	cmd = cmd.UnsetEnv("SHELLZ_TEST_K2", "SHELLZ_TEST_K1", "SHELLZ_TEST_K1")
+	g.Expect(cmd.GetEnv()).To(BeEmpty())
+	g.Expect(cmd.GetUnsetEnv()).To(Equal([]string{"SHELLZ_TEST_K1", "SHELLZ_TEST_K2"}))
+	g.Expect(cmd.MustOutputString(false)).ToNot(ContainSubstring("SHELLZ_TEST_K"))
+
+	cmd = cmd.SetEnv("SHELLZ_TEST_K1", "v3")
+	g.Expect(cmd.GetUnsetEnv()).To(Equal([]string{"SHELLZ_TEST_K2"}))
+	g.Expect(cmd.MustOutputString(false)).To(ContainSubstring("SHELLZ_TEST_K1=v3"))
+
+	cmd = cmd.MergeEnv(map[string]string{"SHELLZ_TEST_K2": "v4"})
+	g.Expect(cmd.GetUnsetEnv()).To(BeEmpty())
+}
+
+func (*CommandSuite) TestSetEnvMode(g *WithT) {
+	g.Expect(os.Setenv("SHELLZ_TEST_K1", "v1")).To(Succeed())
+	defer func() { _ = os.Unsetenv("SHELLZ_TEST_K1") }()
+	g.Expect(os.Setenv("SHELLZ_TEST_K2", "v2")).To(Succeed())
+	defer func() { _ = os.Unsetenv("SHELLZ_TEST_K2") }()
+
+	cmd := shellz.NewCommand("env").SetEcho(false)
+	g.Expect(cmd.GetEnvMode()).To(Equal(shellz.EnvModeInherit))
+	g.Expect(cmd.GetEnviron()).To(ContainElements("SHELLZ_TEST_K1=v1", "SHELLZ_TEST_K2=v2"))
+	g.Expect(slices.IsSorted(cmd.GetEnviron())).To(BeTrue())
+
+	cmd = cmd.SetEnv("SHELLZ_TEST_K1", "v3")
+	g.Expect(cmd.GetEnviron()).To(ContainElement("SHELLZ_TEST_K1=v3"))
+	g.Expect(cmd.GetEnviron()).ToNot(ContainElement("SHELLZ_TEST_K1=v1"))
+
+	cmd = cmd.SetEnvMode(shellz.EnvModeClean).SetEnv("SHELLZ_TEST_K0", "v0")
+	g.Expect(cmd.GetEnvMode()).To(Equal(shellz.EnvModeClean))
+	g.Expect(cmd.GetEnviron()).To(Equal([]string{"SHELLZ_TEST_K0=v0", "SHELLZ_TEST_K1=v3"}))
+	g.Expect(cmd.MustOutputString(false)).To(Equal("SHELLZ_TEST_K0=v0\nSHELLZ_TEST_K1=v3\n"))
+
+	g.Expect(shellz.NewCommand("env").SetEcho(false).SetEnvMode(shellz.EnvModeClean).GetEnviron()).To(BeEmpty())
+	g.Expect(shellz.NewCommand("env").SetEcho(false).SetEnvMode(shellz.EnvModeClean).MustOutputString(false)).To(BeEmpty())
+
+	cmd = shellz.NewCommand("env").
+		SetEcho(false).
+		SetEnvMode(shellz.EnvModeAllowlist).
+		AddEnvAllowlist("SHELLZ_TEST_K2", "SHELLZ_TEST_K3", "SHELLZ_TEST_K2").
+		SetEnv("SHELLZ_TEST_K0", "v0")
+	g.Expect(cmd.GetEnvMode()).To(Equal(shellz.EnvModeAllowlist))
+	g.Expect(cmd.GetEnvAllowlist()).To(Equal([]string{"SHELLZ_TEST_K2", "SHELLZ_TEST_K3"}))
+	g.Expect(cmd.GetEnviron()).To(Equal([]string{"SHELLZ_TEST_K0=v0", "SHELLZ_TEST_K2=v2"}))
+	g.Expect(cmd.UnsetEnv("SHELLZ_TEST_K2").GetEnviron()).To(Equal([]string{"SHELLZ_TEST_K0=v0"}))
+}
+
 func (*CommandSuite) TestSetIn(g *WithT) {
 	r := strings.NewReader("")
 	cmd := shellz.NewCommand("cmd").SetIn(r)
@@ -718,3 +1021,14 @@ func (*CommandSuite) TestSetEcho(g *WithT) {
 	cmd = cmd.SetEcho(false)
 	g.Expect(cmd.GetEcho()).To(PointTo(BeFalse()))
 }
+
+func (*CommandSuite) TestSetCaptureSize(g *WithT) {
+	cmd := shellz.NewCommand("cmd")
+	g.Expect(cmd.GetCaptureSize()).To(Equal(shellz.DefaultCaptureSize))
+	g.Expect(cmd.GetCaptureStdout()).To(BeFalse())
+	g.Expect(cmd.GetCaptureTerminal()).To(BeFalse())
+	cmd = cmd.SetCaptureSize(10).SetCaptureStdout(true).SetCaptureTerminal(true)
+	g.Expect(cmd.GetCaptureSize()).To(Equal(10))
+	g.Expect(cmd.GetCaptureStdout()).To(BeTrue())
+	g.Expect(cmd.GetCaptureTerminal()).To(BeTrue())
+}
@@ -0,0 +1,276 @@
+package shellz
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/ibrt/golang-lib/consolez"
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/memz"
+)
+
+// Pipeline describes a sequence of commands in which the standard output of each command is connected to the
+// standard input of the next one, i.e. "a | b | c". All the commands run concurrently, each with its own Executor,
+// dir, env, context, and timeout. The input of the first command is used as input of the pipeline, while the inputs
+// and echo configurations of the other commands are ignored.
+//
+// Like "set -o pipefail" in Bash, a pipeline fails if any of its commands fails, and the returned *ExecutionError
+// refers to the rightmost failed command (see ExecutionError.GetPipelineStage). Note that a command may fail with
+// SIGPIPE if a later command exits without reading all of its input.
+//
+// The standard error of each command is captured as configured on the command (see Command.SetCaptureSize). Standard
+// output is only captured for the last command, as the others are connected directly to the next.
+type Pipeline struct {
+	cmds []*Command
+	echo *bool
+}
+
+// NewPipeline creates a new Pipeline. It panics if no commands are given.
+func NewPipeline(cmds ...*Command) *Pipeline {
+	errorz.Assertf(len(cmds) > 0, "pipeline is empty")
+
+	return &Pipeline{
+		cmds: memz.ShallowCopySlice(cmds),
+	}
+}
+
+// Pipe creates a new Pipeline which connects the standard output of this command to the standard input of the next.
+func (c *Command) Pipe(next *Command) *Pipeline {
+	return NewPipeline(c, next)
+}
+
+// Pipe appends a command to the pipeline.
+func (p *Pipeline) Pipe(next *Command) *Pipeline {
+	pp := p.clone()
+	pp.cmds = append(pp.cmds, next)
+	return pp
+}
+
+// GetCommands returns the current commands.
+func (p *Pipeline) GetCommands() []*Command {
+	return memz.ShallowCopySlice(p.cmds)
+}
+
+// SetEcho configures echo.
+func (p *Pipeline) SetEcho(echo bool) *Pipeline {
+	pp := p.clone()
+	pp.echo = memz.Ptr(echo)
+	return pp
+}
+
+// GetEcho returns the current echo configuration.
+func (p *Pipeline) GetEcho() *bool {
+	if p.echo == nil {
+		return nil
+	}
+	return memz.Ptr(*p.echo)
+}
+
+// Run runs the pipeline.
+func (p *Pipeline) Run() error {
+	p.maybeEcho(true)
+
+	return p.run(os.Stdout, func(_ int) io.Writer { return os.Stderr })
+}
+
+// MustRun is like Run but panics on error.
+func (p *Pipeline) MustRun() {
+	errorz.MaybeMustWrap(p.Run())
+}
+
+// Output runs the pipeline and returns a buffer containing the resulting standard output of the last command.
+// If "echoStderr" is false, the standard error of each command is discarded (but still captured).
+func (p *Pipeline) Output(echoStderr bool) ([]byte, error) {
+	p.maybeEcho(false)
+
+	outBuf := &bytes.Buffer{}
+
+	getStderr := func(_ int) io.Writer {
+		if echoStderr {
+			return os.Stderr
+		}
+		return nil
+	}
+
+	if err := p.run(outBuf, getStderr); err != nil {
+		return nil, err
+	}
+
+	return outBuf.Bytes(), nil
+}
+
+// MustOutput is like Output but panics on error.
+func (p *Pipeline) MustOutput(echoStderr bool) []byte {
+	out, err := p.Output(echoStderr)
+	errorz.MaybeMustWrap(err)
+	return out
+}
+
+// OutputString is like Output but returns a string.
+func (p *Pipeline) OutputString(echoStderr bool) (string, error) {
+	buf, err := p.Output(echoStderr)
+	if err != nil {
+		return "", errorz.Wrap(err)
+	}
+
+	return string(buf), nil
+}
+
+// MustOutputString is like OutputString but panics on error.
+func (p *Pipeline) MustOutputString(echoStderr bool) string {
+	buf, err := p.OutputString(echoStderr)
+	errorz.MaybeMustWrap(err)
+	return buf
+}
+
+// Lines runs the pipeline and calls "lineFunc" with each line of standard output of the last command, and each line
+// of standard error of all the commands.
+func (p *Pipeline) Lines(lineFunc func(string)) error {
+	p.maybeEcho(true)
+
+	m := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	wg.Add(len(p.cmds) + 1)
+
+	callLineFunc := func(line string) {
+		m.Lock()
+		defer m.Unlock()
+		lineFunc(line)
+	}
+
+	outR, outW := io.Pipe()
+	go handleLines(wg, outR, callLineFunc)
+
+	errWs := make([]*io.PipeWriter, len(p.cmds))
+
+	for i := range errWs {
+		var errR *io.PipeReader
+		errR, errWs[i] = io.Pipe()
+		go handleLines(wg, errR, callLineFunc)
+	}
+
+	err := p.run(outW, func(i int) io.Writer { return errWs[i] })
+
+	_ = outW.Close()
+	for _, errW := range errWs {
+		_ = errW.Close()
+	}
+
+	wg.Wait()
+	return err
+}
+
+// MustLines is like Lines but panics on error.
+func (p *Pipeline) MustLines(lineFunc func(string)) {
+	errorz.MaybeMustWrap(p.Lines(lineFunc))
+}
+
+type pipelineStage struct {
+	c       *Command
+	cmd     *exec.Cmd
+	ctx     context.Context
+	cp      *capture
+	started bool
+	err     error
+}
+
+// run starts all the commands, waits for them to complete, and returns an *ExecutionError for the rightmost failed
+// command (if any).
+func (p *Pipeline) run(stdout io.Writer, getStderr func(i int) io.Writer) error {
+	stages := make([]*pipelineStage, 0, len(p.cmds))
+	files := make([]*os.File, 0, 2*(len(p.cmds)-1))
+
+	for i, c := range p.cmds {
+		cmd, ctx, done := c.newCmd()
+		defer done()
+
+		cp := c.newCapture()
+		cmd.Stderr = cp.wrapStderr(getStderr(i))
+		stages = append(stages, &pipelineStage{c: c, cmd: cmd, ctx: ctx, cp: cp})
+
+		if i > 0 {
+			r, w, err := os.Pipe()
+			errorz.MaybeMustWrap(err)
+			files = append(files, r, w)
+			stages[i-1].cmd.Stdout = w
+			cmd.Stdin = r
+		}
+	}
+
+	lastStage := stages[len(stages)-1]
+	lastStage.cmd.Stdout = lastStage.cp.wrapStdout(stdout)
+
+	for _, s := range stages {
+		if s.err = s.c.executor.ExecCmdStart(s.c, s.cmd); s.err != nil {
+			for _, s := range stages {
+				if s.started {
+					_ = s.c.executor.ExecCmdSignal(s.c, s.cmd, syscall.SIGKILL)
+				}
+			}
+			break
+		}
+		s.started = true
+	}
+
+	// The pipe ends are now owned by the child processes.
+	for _, f := range files {
+		_ = f.Close()
+	}
+
+	for _, s := range stages {
+		if s.started {
+			s.err = s.c.executor.ExecCmdWait(s.c, s.cmd)
+		}
+	}
+
+	for i := len(stages) - 1; i >= 0; i-- {
+		if stages[i].err != nil {
+			e := stages[i].cp.attach(newExecutionError(stages[i].ctx, stages[i].err, stages[i].c))
+			e.pipelineStage = i
+			e.pipelineLength = len(stages)
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (p *Pipeline) maybeEcho(defaultEcho bool) {
+	if (p.echo == nil && !defaultEcho) || (p.echo != nil && !*p.echo) {
+		return
+	}
+
+	first := p.cmds[0]
+	prefix, words := renderCommandParts(first.dir, first.env, first.cmd, first.params)
+
+	if first.dir != "" {
+		prefix = "(" + prefix
+		words[len(words)-1] += ")"
+	}
+
+	params := words[1:]
+
+	for _, c := range p.cmds[1:] {
+		params = append(params, "|", renderPipelineStage(c))
+	}
+
+	consolez.DefaultCLI.QuotedCommand(prefix, words[0], params...)
+}
+
+func (p *Pipeline) clone() *Pipeline {
+	pp := &Pipeline{
+		cmds: memz.ShallowCopySlice(p.cmds),
+		echo: nil,
+	}
+
+	if p.echo != nil {
+		pp.echo = memz.Ptr(*p.echo)
+	}
+
+	return pp
+}
@@ -0,0 +1,235 @@
+package shellz_test
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+
+	"github.com/ibrt/golang-lib/consolez"
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+)
+
+type PipelineSuite struct {
+	// intentionally empty
+}
+
+func TestPipelineSuite(t *testing.T) {
+	fixturez.RunSuite(t, &PipelineSuite{})
+}
+
+func (*PipelineSuite) TestRun_Success(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	g.Expect(
+		shellz.NewCommand("cat").SetIn(strings.NewReader("a\nb\nc\n")).
+			Pipe(shellz.NewCommand("grep", "-v", "b")).
+			Pipe(shellz.NewCommand("tr", "a-z", "A-Z")).
+			Run()).
+		To(Succeed())
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal(fmt.Sprintf("%v cat \x1b[2m| grep -v b | tr a-z A-Z\x1b[0m\nA\nC\n", consolez.IconRunner)))
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*PipelineSuite) TestRun_Error(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	err := shellz.NewPipeline(
+		shellz.NewCommand("sh", "-c", "echo out; echo err1 >&2; exit 3"),
+		shellz.NewCommand("sh", "-c", "cat; exit 4"),
+		shellz.NewCommand("cat")).
+		SetEcho(false).
+		Run()
+	g.Expect(err).To(MatchError("execution error: pipeline stage 2 of 3: sh -c 'cat; exit 4': exit status 4"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCommand()).To(Equal("sh"))
+	g.Expect(eErr.GetParams()).To(Equal([]string{"-c", "cat; exit 4"}))
+	g.Expect(eErr.GetExitCode()).To(Equal(4))
+	g.Expect(eErr.GetPipelineStage()).To(Equal(1))
+	g.Expect(eErr.GetPipelineLength()).To(Equal(3))
+
+	xErr, ok := errorz.As[*exec.ExitError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(xErr.ExitCode()).To(Equal(4))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal("out\n"))
+	g.Expect(errBuf).To(Equal("err1\n"))
+}
+
+func (*PipelineSuite) TestMustRun(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	g.Expect(func() {
+		shellz.NewCommand("echo", "input").Pipe(shellz.NewCommand("cat")).SetEcho(false).MustRun()
+	}).ToNot(Panic())
+
+	g.Expect(func() {
+		shellz.NewCommand("echo", "input").Pipe(shellz.NewCommand("false")).SetEcho(false).MustRun()
+	}).To(PanicWith(MatchError("execution error: pipeline stage 2 of 2: false: exit status 1")))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal("input\n"))
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*PipelineSuite) TestOutput_Success(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	out, err := shellz.NewCommand("printf", `3\n1\n2\n`).
+		Pipe(shellz.NewCommand("sort")).
+		Pipe(shellz.NewCommand("head", "-n", "2")).
+		OutputString(true)
+	g.Expect(err).To(Succeed())
+	g.Expect(out).To(Equal("1\n2\n"))
+
+	g.Expect(shellz.NewCommand("echo", "a b").Pipe(shellz.NewCommand("wc", "-w")).MustOutputString(false)).
+		To(MatchRegexp(`^\s*2\n$`))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(BeEmpty())
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*PipelineSuite) TestOutput_Error_EchoStderrFalse(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	out, err := shellz.NewPipeline(
+		shellz.NewCommand("sh", "-c", "echo err1 >&2; exit 2"),
+		shellz.NewCommand("sh", "-c", "echo err2 >&2; exit 3"),
+		shellz.NewCommand("sh", "-c", "cat; echo err3 >&2")).
+		Output(false)
+	g.Expect(out).To(BeNil())
+	g.Expect(err).To(MatchError("execution error: pipeline stage 2 of 3: sh -c 'echo err2 >&2; exit 3': exit status 3"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(3))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err2\n"))
+
+	g.Expect(func() {
+		shellz.NewCommand("true").Pipe(shellz.NewCommand("false")).MustOutput(false)
+	}).To(PanicWith(MatchError("execution error: pipeline stage 2 of 2: false: exit status 1")))
+
+	g.Expect(func() {
+		shellz.NewCommand("true").Pipe(shellz.NewCommand("false")).MustOutputString(false)
+	}).To(PanicWith(MatchError("execution error: pipeline stage 2 of 2: false: exit status 1")))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(BeEmpty())
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*PipelineSuite) TestOutput_Error_EchoStderrTrue(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	_, err := shellz.NewCommand("sh", "-c", "echo err1 >&2; exit 2").Pipe(shellz.NewCommand("cat")).Output(true)
+	g.Expect(err).To(MatchError("execution error: pipeline stage 1 of 2: sh -c 'echo err1 >&2; exit 2': exit status 2"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err1\n"))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(BeEmpty())
+	g.Expect(errBuf).To(Equal("err1\n"))
+}
+
+func (*PipelineSuite) TestOutput_Error_Start(g *WithT) {
+	startTime := time.Now()
+
+	_, err := shellz.NewPipeline(
+		shellz.NewCommand("sleep", "10"),
+		shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8"),
+		shellz.NewCommand("cat")).
+		Output(false)
+	g.Expect(time.Since(startTime)).To(BeNumerically("<", 5*time.Second))
+	g.Expect(err).To(MatchError(`execution error: pipeline stage 2 of 3: cae0e988-f55b-4803-a471-a877b686d1a8: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH`))
+}
+
+func (*PipelineSuite) TestOutput_Error_Timeout(g *WithT) {
+	_, err := shellz.NewCommand("sleep", "10").SetTimeout(100 * time.Millisecond).
+		Pipe(shellz.NewCommand("cat")).
+		Output(false)
+	g.Expect(err).To(MatchError("execution error: pipeline stage 1 of 2: sleep 10: timed out: signal: terminated"))
+	g.Expect(err).To(MatchError(shellz.ErrTimeout))
+}
+
+func (*PipelineSuite) TestLines_Success(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	receivedLines := make([]string, 0)
+
+	g.Expect(
+		shellz.NewCommand("sh", "-c", "echo 1; echo 2; echo err1 >&2").
+			Pipe(shellz.NewCommand("sh", "-c", "sed 's/^/x/'; echo err2 >&2")).
+			Lines(func(line string) {
+				receivedLines = append(receivedLines, line)
+			})).
+		To(Succeed())
+
+	g.Expect(receivedLines).To(ConsistOf("x1", "x2", "err1", "err2"))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(HavePrefix(fmt.Sprintf("%v sh ", consolez.IconRunner)))
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*PipelineSuite) TestLines_Error(g *WithT) {
+	receivedLines := make([]string, 0)
+
+	err := shellz.NewCommand("sh", "-c", "echo 1; exit 5").
+		Pipe(shellz.NewCommand("cat")).
+		SetEcho(false).
+		Lines(func(line string) {
+			receivedLines = append(receivedLines, line)
+		})
+	g.Expect(err).To(MatchError("execution error: pipeline stage 1 of 2: sh -c 'echo 1; exit 5': exit status 5"))
+	g.Expect(receivedLines).To(Equal([]string{"1"}))
+
+	g.Expect(func() {
+		shellz.NewCommand("sh", "-c", "echo 1; exit 5").
+			Pipe(shellz.NewCommand("cat")).
+			SetEcho(false).
+			MustLines(func(string) {})
+	}).To(PanicWith(MatchError("execution error: pipeline stage 1 of 2: sh -c 'echo 1; exit 5': exit status 5")))
+}
+
+func (*PipelineSuite) TestNewPipeline(g *WithT) {
+	g.Expect(func() { shellz.NewPipeline() }).To(PanicWith(MatchError("pipeline is empty")))
+
+	c1 := shellz.NewCommand("c1")
+	c2 := shellz.NewCommand("c2")
+	c3 := shellz.NewCommand("c3")
+
+	p := c1.Pipe(c2)
+	g.Expect(p.GetCommands()).To(Equal([]*shellz.Command{c1, c2}))
+	g.Expect(p.Pipe(c3).GetCommands()).To(Equal([]*shellz.Command{c1, c2, c3}))
+	g.Expect(p.GetCommands()).To(Equal([]*shellz.Command{c1, c2}))
+}
+
+func (*PipelineSuite) TestSetEcho(g *WithT) {
+	p := shellz.NewPipeline(shellz.NewCommand("cmd"))
+	g.Expect(p.GetEcho()).To(BeNil())
+	p = p.SetEcho(true)
+	g.Expect(p.GetEcho()).To(PointTo(BeTrue()))
+	p = p.SetEcho(false).Pipe(shellz.NewCommand("cmd"))
+	g.Expect(p.GetEcho()).To(PointTo(BeFalse()))
+}
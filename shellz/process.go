@@ -0,0 +1,325 @@
+package shellz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+var (
+	// ErrProcessExited is matched (using "errors.Is") by the error returned by a readiness probe when the process exits
+	// before becoming ready.
+	ErrProcessExited = errors.New("process exited")
+)
+
+const (
+	// DefaultProbeInterval is the interval between attempts of the port readiness probe.
+	DefaultProbeInterval = 100 * time.Millisecond
+
+	// ProcessLineHistorySize is the maximum number of output lines retained by a Process.
+	ProcessLineHistorySize = 1000
+)
+
+// Process describes a command running in the background (see Command.Start).
+type Process struct {
+	c        *Command
+	cmd      *exec.Cmd
+	m        *sync.Mutex
+	lm       *sync.Mutex
+	lines    []string
+	subs     map[int]func(string)
+	nextSub  int
+	doneC    chan struct{}
+	exitCode int
+	err      error
+}
+
+// Start starts the command in the background and returns a *Process. The command runs in its own process group, so
+// that signals sent to the process reach its children as well. Its standard output and error are read line by line:
+// lines are retained in a bounded history (see Process.GetLines) and passed to the subscribed functions (see
+// Process.Subscribe). The process is waited for in the background, see Process.Wait.
+func (c *Command) Start() (*Process, error) {
+	c.maybeEcho(true)
+	cmd, ctx, done := c.newCmd()
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	outR, outW := io.Pipe()
+	errR, errW := io.Pipe()
+	cp := c.newCapture()
+	cmd.Stdout = cp.wrapStdout(outW)
+	cmd.Stderr = cp.wrapStderr(errW)
+
+	p := &Process{
+		c:        c,
+		cmd:      cmd,
+		m:        &sync.Mutex{},
+		lm:       &sync.Mutex{},
+		lines:    make([]string, 0),
+		subs:     make(map[int]func(string)),
+		doneC:    make(chan struct{}),
+		exitCode: -1,
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	go handleLines(wg, outR, p.handleLine)
+	go handleLines(wg, errR, p.handleLine)
+
+	closeAll := func() {
+		_ = outW.Close()
+		_ = errW.Close()
+		wg.Wait()
+		done()
+	}
+
+	if err := c.executor.ExecCmdStart(c, cmd); err != nil {
+		closeAll()
+		return nil, cp.attach(newExecutionError(ctx, err, c))
+	}
+
+	go func() {
+		err := c.executor.ExecCmdWait(c, cmd)
+		closeAll()
+
+		p.m.Lock()
+		defer p.m.Unlock()
+
+		if err != nil {
+			eErr := cp.attach(newExecutionError(ctx, err, c))
+			p.exitCode = eErr.GetExitCode()
+			p.err = eErr
+		} else {
+			p.exitCode = 0
+		}
+
+		close(p.doneC)
+	}()
+
+	return p, nil
+}
+
+// MustStart is like Start but panics on error.
+func (c *Command) MustStart() *Process {
+	p, err := c.Start()
+	errorz.MaybeMustWrap(err)
+	return p
+}
+
+// GetCommand returns the originating command.
+func (p *Process) GetCommand() *Command {
+	return p.c
+}
+
+// GetPid returns the process ID, or -1 if not available.
+func (p *Process) GetPid() int {
+	if p.cmd.Process == nil {
+		return -1
+	}
+	return p.cmd.Process.Pid
+}
+
+// GetExitCode returns the exit code, or -1 if the process is still running or it was terminated by a signal.
+func (p *Process) GetExitCode() int {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.exitCode
+}
+
+// GetLines returns the most recent lines of output (up to ProcessLineHistorySize).
+func (p *Process) GetLines() []string {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return append([]string(nil), p.lines...)
+}
+
+// Subscribe registers a function which is called with each subsequent line of output, and returns a function that
+// unregisters it. Subscribed functions are never called concurrently.
+func (p *Process) Subscribe(lineFunc func(string)) func() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.subscribe(lineFunc)
+}
+
+func (p *Process) subscribe(lineFunc func(string)) func() {
+	id := p.nextSub
+	p.nextSub++
+	p.subs[id] = lineFunc
+
+	return func() {
+		p.m.Lock()
+		defer p.m.Unlock()
+		delete(p.subs, id)
+	}
+}
+
+// Done returns a channel that is closed when the process has exited and all its output has been handled.
+func (p *Process) Done() <-chan struct{} {
+	return p.doneC
+}
+
+// Wait waits for the process to exit. It returns an *ExecutionError if the process fails. It can be called multiple
+// times, including concurrently.
+func (p *Process) Wait() error {
+	<-p.doneC
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.err
+}
+
+// MustWait is like Wait but panics on error.
+func (p *Process) MustWait() {
+	errorz.MaybeMustWrap(p.Wait())
+}
+
+// Signal sends the given signal to the process group. It returns an error matching "os.ErrProcessDone" if the
+// process is not running.
+func (p *Process) Signal(sig syscall.Signal) error {
+	return p.c.executor.ExecCmdSignal(p.c, p.cmd, sig)
+}
+
+// Kill sends SIGKILL to the process group.
+func (p *Process) Kill() error {
+	return p.Signal(syscall.SIGKILL)
+}
+
+// Stop sends SIGTERM to the process group, followed by SIGKILL if the process does not exit within the grace period
+// of the command (see Command.SetGracePeriod), and waits for it to exit. Unlike Wait, it does not return the error
+// caused by the process being terminated.
+func (p *Process) Stop() error {
+	if err := p.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return errorz.Wrap(err)
+	}
+
+	t := time.NewTimer(p.c.gracePeriod)
+	defer t.Stop()
+
+	select {
+	case <-p.doneC:
+		return nil
+	case <-t.C:
+	}
+
+	if err := p.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return errorz.Wrap(err)
+	}
+
+	<-p.doneC
+	return nil
+}
+
+// WaitForLine waits until a line of output matches the given regexp (including lines in the history, see GetLines),
+// and returns it. It fails if the context is done or the process exits first.
+func (p *Process) WaitForLine(ctx context.Context, re *regexp.Regexp) (string, error) {
+	matchC := make(chan string, 1)
+
+	matchFunc := func(line string) {
+		if re.MatchString(line) {
+			select {
+			case matchC <- line:
+			default:
+			}
+		}
+	}
+
+	p.m.Lock()
+	for _, line := range p.lines {
+		matchFunc(line)
+	}
+	unsubscribe := p.subscribe(matchFunc)
+	p.m.Unlock()
+	defer unsubscribe()
+
+	select {
+	case line := <-matchC:
+		return line, nil
+	case <-p.doneC:
+		select {
+		case line := <-matchC:
+			return line, nil
+		default:
+			return "", p.newExitedError()
+		}
+	case <-ctx.Done():
+		return "", errorz.Wrap(ctx.Err())
+	}
+}
+
+// MustWaitForLine is like WaitForLine but panics on error.
+func (p *Process) MustWaitForLine(ctx context.Context, re *regexp.Regexp) string {
+	line, err := p.WaitForLine(ctx, re)
+	errorz.MaybeMustWrap(err)
+	return line
+}
+
+// WaitForPort waits until a TCP connection to the given address succeeds, trying every DefaultProbeInterval.
+// It fails if the context is done or the process exits first.
+func (p *Process) WaitForPort(ctx context.Context, address string) error {
+	t := time.NewTicker(DefaultProbeInterval)
+	defer t.Stop()
+
+	for {
+		conn, err := p.c.executor.NetDialContext(ctx, p.c, "tcp", address)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+
+		select {
+		case <-p.doneC:
+			return p.newExitedError()
+		case <-ctx.Done():
+			return errorz.Wrap(ctx.Err())
+		case <-t.C:
+		}
+	}
+}
+
+// MustWaitForPort is like WaitForPort but panics on error.
+func (p *Process) MustWaitForPort(ctx context.Context, address string) {
+	errorz.MaybeMustWrap(p.WaitForPort(ctx, address))
+}
+
+func (p *Process) handleLine(line string) {
+	p.lm.Lock()
+	defer p.lm.Unlock()
+
+	p.m.Lock()
+	if len(p.lines) >= ProcessLineHistorySize {
+		p.lines = append(p.lines[:0], p.lines[len(p.lines)-ProcessLineHistorySize+1:]...)
+	}
+	p.lines = append(p.lines, line)
+
+	subs := make([]func(string), 0, len(p.subs))
+	for _, id := range slices.Sorted(maps.Keys(p.subs)) {
+		subs = append(subs, p.subs[id])
+	}
+	p.m.Unlock()
+
+	for _, sub := range subs {
+		sub(line)
+	}
+}
+
+func (p *Process) newExitedError() error {
+	if err := p.Wait(); err != nil {
+		return errorz.Wrap(err, ErrProcessExited)
+	}
+
+	return errorz.Wrap(ErrProcessExited)
+}
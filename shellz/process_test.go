@@ -0,0 +1,215 @@
+package shellz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ibrt/golang-lib/consolez"
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
+)
+
+type ProcessSuite struct {
+	// intentionally empty
+}
+
+func TestProcessSuite(t *testing.T) {
+	fixturez.RunSuite(t, &ProcessSuite{})
+}
+
+func (*ProcessSuite) TestStart_Success(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	m := &sync.Mutex{}
+	receivedLines := make([]string, 0)
+
+	inR, inW := io.Pipe()
+
+	p := shellz.NewCommand("sh", "-c", "read x; echo out $x; echo err >&2").
+		SetIn(inR).
+		MustStart()
+
+	g.Expect(p.GetPid()).To(BeNumerically(">", 0))
+	g.Expect(p.GetCommand().GetParams()).To(Equal([]string{"-c", "read x; echo out $x; echo err >&2"}))
+
+	unsubscribe := p.Subscribe(func(line string) {
+		m.Lock()
+		defer m.Unlock()
+		receivedLines = append(receivedLines, line)
+	})
+	defer unsubscribe()
+
+	_, err := inW.Write([]byte("in\n"))
+	g.Expect(err).To(Succeed())
+	g.Expect(inW.Close()).To(Succeed())
+
+	g.Expect(p.Wait()).To(Succeed())
+	g.Expect(p.Wait()).To(Succeed())
+	g.Expect(p.Done()).To(BeClosed())
+	g.Expect(p.GetExitCode()).To(Equal(0))
+	g.Expect(p.GetLines()).To(ConsistOf("out in", "err"))
+	g.Expect(receivedLines).To(ConsistOf("out in", "err"))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(HavePrefix(fmt.Sprintf("%v sh ", consolez.IconRunner)))
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*ProcessSuite) TestStart_Error(g *WithT) {
+	p, err := shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8").SetEcho(false).Start()
+	g.Expect(p).To(BeNil())
+	g.Expect(err).To(MatchError(`execution error: cae0e988-f55b-4803-a471-a877b686d1a8: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH`))
+
+	g.Expect(func() {
+		shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8").SetEcho(false).MustStart()
+	}).To(Panic())
+}
+
+func (*ProcessSuite) TestWait_Error(g *WithT) {
+	p := shellz.NewCommand("sh", "-c", "echo err >&2; exit 3").SetEcho(false).MustStart()
+	err := p.Wait()
+	g.Expect(err).To(MatchError("execution error: sh -c 'echo err >&2; exit 3': exit status 3"))
+	g.Expect(p.GetExitCode()).To(Equal(3))
+	g.Expect(p.GetLines()).To(Equal([]string{"err"}))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(3))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err\n"))
+
+	g.Expect(func() { p.MustWait() }).To(PanicWith(MatchError("execution error: sh -c 'echo err >&2; exit 3': exit status 3")))
+}
+
+func (*ProcessSuite) TestSignal(g *WithT) {
+	startTime := time.Now()
+	p := shellz.NewCommand("sh", "-c", "sleep 10 & wait").SetEcho(false).MustStart()
+	g.Expect(p.GetExitCode()).To(Equal(-1))
+	g.Expect(p.Signal(syscall.SIGTERM)).To(Succeed())
+	g.Expect(p.Wait()).To(MatchError("execution error: sh -c 'sleep 10 & wait': signal: terminated"))
+	g.Expect(p.GetExitCode()).To(Equal(-1))
+	g.Expect(time.Since(startTime)).To(BeNumerically("<", 5*time.Second))
+
+	p = shellz.NewCommand("true").SetEcho(false).MustStart()
+	g.Expect(p.Wait()).To(Succeed())
+	g.Expect(errors.Is(p.Signal(syscall.SIGINT), os.ErrProcessDone)).To(BeTrue())
+
+	p = shellz.NewCommand("sleep", "10").SetEcho(false).MustStart()
+	g.Expect(p.Kill()).To(Succeed())
+	g.Expect(p.Wait()).To(MatchError("execution error: sleep 10: signal: killed"))
+}
+
+func (*ProcessSuite) TestStop(g *WithT) {
+	p := shellz.NewCommand("sleep", "10").SetEcho(false).MustStart()
+	g.Expect(p.Stop()).To(Succeed())
+	g.Expect(p.Wait()).To(MatchError("execution error: sleep 10: signal: terminated"))
+	g.Expect(p.Stop()).To(Succeed())
+
+	startTime := time.Now()
+	p = shellz.NewCommand("sh", "-c", `trap "" TERM; echo ready; sleep 10`).
+		SetEcho(false).
+		SetGracePeriod(200 * time.Millisecond).
+		MustStart()
+	p.MustWaitForLine(context.Background(), regexp.MustCompile("^ready$"))
+	g.Expect(p.Stop()).To(Succeed())
+	g.Expect(p.Wait()).To(MatchError(`execution error: sh -c 'trap "" TERM; echo ready; sleep 10': signal: killed`))
+	g.Expect(time.Since(startTime)).To(BeNumerically(">=", 200*time.Millisecond))
+	g.Expect(time.Since(startTime)).To(BeNumerically("<", 5*time.Second))
+}
+
+func (*ProcessSuite) TestWaitForLine(g *WithT) {
+	p := shellz.NewCommand("sh", "-c", "echo starting; sleep 0.1; echo listening on 1234 >&2; sleep 10").
+		SetEcho(false).
+		MustStart()
+	defer func() { _ = p.Kill() }()
+
+	line, err := p.WaitForLine(context.Background(), regexp.MustCompile(`listening on \d+`))
+	g.Expect(err).To(Succeed())
+	g.Expect(line).To(Equal("listening on 1234"))
+
+	g.Expect(p.MustWaitForLine(context.Background(), regexp.MustCompile(`^start`))).To(Equal("starting"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = p.WaitForLine(ctx, regexp.MustCompile(`never`))
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+}
+
+func (*ProcessSuite) TestWaitForLine_Exited(g *WithT) {
+	p := shellz.NewCommand("sh", "-c", "echo starting; exit 2").SetEcho(false).MustStart()
+
+	_, err := p.WaitForLine(context.Background(), regexp.MustCompile(`ready`))
+	g.Expect(err).To(MatchError(shellz.ErrProcessExited))
+	g.Expect(err).To(MatchError("process exited: execution error: sh -c 'echo starting; exit 2': exit status 2"))
+
+	p = shellz.NewCommand("true").SetEcho(false).MustStart()
+
+	g.Expect(func() {
+		p.MustWaitForLine(context.Background(), regexp.MustCompile(`ready`))
+	}).To(PanicWith(MatchError("process exited")))
+}
+
+func (*ProcessSuite) TestWaitForPort(g *WithT) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).To(Succeed())
+	address := l.Addr().String()
+	g.Expect(l.Close()).To(Succeed())
+
+	p := shellz.NewCommand("sleep", "10").SetEcho(false).MustStart()
+	defer func() { _ = p.Kill() }()
+
+	time.AfterFunc(300*time.Millisecond, func() {
+		l, err := net.Listen("tcp", address)
+		if err == nil {
+			go func() {
+				<-p.Done()
+				_ = l.Close()
+			}()
+		}
+	})
+
+	g.Expect(p.WaitForPort(context.Background(), address)).To(Succeed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g.Expect(func() {
+		p.MustWaitForPort(ctx, "127.0.0.1:1")
+	}).To(PanicWith(MatchError(context.Canceled)))
+
+	g.Expect(p.Kill()).To(Succeed())
+	g.Expect(p.WaitForPort(context.Background(), "127.0.0.1:1")).To(MatchError(shellz.ErrProcessExited))
+}
+
+func (*ProcessSuite) TestStart_Mock(g *WithT, ctrl *gomock.Controller) {
+	m := tshellz.NewMockExecutor(ctrl)
+
+	m.EXPECT().ExecCmdStart(gomock.Any(), gomock.Any()).Return(nil)
+	m.EXPECT().ExecCmdWait(gomock.Any(), gomock.Any()).Return(nil)
+	m.EXPECT().NetDialContext(gomock.Any(), gomock.Any(), "tcp", "localhost:8080").Return(&net.TCPConn{}, nil)
+
+	p := shellz.NewCommand("server").SetEcho(false).SetExecutor(m).MustStart()
+	g.Expect(p.GetPid()).To(Equal(-1))
+	p.MustWaitForPort(context.Background(), "localhost:8080")
+	g.Expect(p.Wait()).To(Succeed())
+
+	m.EXPECT().ExecCmdStart(gomock.Any(), gomock.Any()).Return(errorz.Errorf("start error"))
+
+	_, err := shellz.NewCommand("server").SetEcho(false).SetExecutor(m).Start()
+	g.Expect(err).To(MatchError("execution error: server: start error"))
+}
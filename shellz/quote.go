@@ -0,0 +1,272 @@
+package shellz
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/memz"
+)
+
+var (
+	safeWordRegexp = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+	envNameRegexp  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	assignRegexp   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+)
+
+// Quote quotes a string for use as a word in a POSIX shell. Strings which only contain safe characters are returned
+// unchanged, others are enclosed in single quotes.
+func Quote(s string) string {
+	if safeWordRegexp.MatchString(s) {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// String renders the command as a POSIX shell command line which can be copied and pasted, e.g.
+// "cd /tmp && K=v cmd 'a param'". The dir is rendered as a "cd dir &&" prefix, and the env as "KEY=value" prefixes
+// (sorted by key). Variables whose names are not valid in the shell are passed through "env" instead. The env mode
+// (see SetEnvMode) and the unset variables (see UnsetEnv) are not rendered. See ParseCommand for the inverse.
+func (c *Command) String() string {
+	return renderCommand(c.dir, c.env, c.cmd, c.params)
+}
+
+// ParseCommand parses a POSIX shell command line into a *Command. It supports the subset of the shell syntax produced
+// by Command.String: an optional "cd dir &&" prefix, followed by optional "KEY=value" prefixes, an optional
+// "env KEY=value..." prefix (which is parsed as part of the env, not as the command), the command, and its params. Words can be quoted using single quotes, double quotes, and backslashes. Other shell features (e.g. variable
+// expansion, globs, redirections, and pipes) are not supported and cause an error.
+func ParseCommand(s string) (*Command, error) {
+	words, err := splitWords(s)
+	if err != nil {
+		return nil, errorz.Wrap(err)
+	}
+
+	dir := ""
+	env := make(map[string]string)
+
+	if len(words) >= 3 && words[0].isPlain("cd") && words[2].isPlain("&&") {
+		if words[1].isOperator {
+			return nil, errorz.Errorf("unexpected '%v' in: %v", words[1].value, s)
+		}
+
+		dir = words[1].value
+		words = words[3:]
+	}
+
+	for len(words) > 0 && words[0].assignIndex > 0 {
+		env[words[0].value[:words[0].assignIndex]] = words[0].value[words[0].assignIndex+1:]
+		words = words[1:]
+	}
+
+	if len(words) > 0 && words[0].isPlain("env") {
+		n := 1
+		for n < len(words) && !words[n].isOperator && strings.Index(words[n].value, "=") > 0 {
+			n++
+		}
+
+		if n > 1 && n < len(words) {
+			for _, w := range words[1:n] {
+				k, v, _ := strings.Cut(w.value, "=")
+				env[k] = v
+			}
+
+			words = words[n:]
+		}
+	}
+
+	if len(words) == 0 {
+		return nil, errorz.Errorf("missing command in: %v", s)
+	}
+
+	for _, w := range words {
+		if w.isOperator {
+			return nil, errorz.Errorf("unexpected '%v' in: %v", w.value, s)
+		}
+	}
+
+	return NewCommand(words[0].value, memz.TransformSlice(words[1:], func(w *word) string { return w.value })...).
+		SetDir(dir).
+		MergeEnv(env), nil
+}
+
+// MustParseCommand is like ParseCommand but panics on error.
+func MustParseCommand(s string) *Command {
+	c, err := ParseCommand(s)
+	errorz.MaybeMustWrap(err)
+	return c
+}
+
+// String renders the pipeline as a POSIX shell command line, joining its commands (see Command.String) with "|".
+// Commands with a dir are enclosed in parentheses, so that the "cd" only applies to them.
+func (p *Pipeline) String() string {
+	return strings.Join(memz.TransformSlice(p.cmds, renderPipelineStage), " | ")
+}
+
+// renderPipelineStage renders a command within a pipeline, see Pipeline.String.
+func renderPipelineStage(c *Command) string {
+	if c.dir != "" {
+		return "(" + c.String() + ")"
+	}
+
+	return c.String()
+}
+
+// renderCommand renders a command line, see Command.String.
+func renderCommand(dir string, env map[string]string, cmd string, params []string) string {
+	prefix, words := renderCommandParts(dir, env, cmd, params)
+
+	if prefix != "" {
+		return prefix + " " + strings.Join(words, " ")
+	}
+
+	return strings.Join(words, " ")
+}
+
+// renderCommandParts renders a command line, returning the quoted dir and env prefix (possibly empty) separately from
+// the quoted command and params.
+func renderCommandParts(dir string, env map[string]string, cmd string, params []string) (string, []string) {
+	prefix := make([]string, 0)
+	envPrefix := make([]string, 0)
+
+	if dir != "" {
+		prefix = append(prefix, "cd", Quote(dir), "&&")
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		if envNameRegexp.MatchString(k) {
+			prefix = append(prefix, k+"="+Quote(env[k]))
+		} else {
+			envPrefix = append(envPrefix, Quote(k+"="+env[k]))
+		}
+	}
+
+	if len(envPrefix) > 0 {
+		prefix = append(append(prefix, "env"), envPrefix...)
+	}
+
+	quotedCmd := Quote(cmd)
+	if assignRegexp.MatchString(quotedCmd) {
+		quotedCmd = "'" + cmd + "'"
+	}
+
+	return strings.Join(prefix, " "), append([]string{quotedCmd}, memz.TransformSlice(params, Quote)...)
+}
+
+type word struct {
+	value       string
+	isOperator  bool
+	assignIndex int
+}
+
+func (w *word) isPlain(value string) bool {
+	return w.value == value && (value == "&&") == w.isOperator
+}
+
+// splitWords splits a command line into words, removing the quotes. For words starting with an unquoted "KEY=", the
+// index of the "=" is stored in "assignIndex".
+func splitWords(s string) ([]*word, error) {
+	words := make([]*word, 0)
+	buf := &strings.Builder{}
+	var cur *word
+	isQuoted := false
+
+	begin := func(quoted bool) {
+		if cur == nil {
+			cur = &word{assignIndex: -1}
+			buf.Reset()
+			isQuoted = false
+		}
+		if quoted && cur.assignIndex < 0 {
+			isQuoted = true
+		}
+	}
+
+	flush := func() {
+		if cur != nil {
+			cur.value = buf.String()
+			words = append(words, cur)
+			cur = nil
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch ch := s[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			flush()
+			i++
+		case ch == '&':
+			if cur != nil || i+1 >= len(s) || s[i+1] != '&' {
+				return nil, errorz.Errorf("unsupported '&' in: %v", s)
+			}
+			words = append(words, &word{value: "&&", isOperator: true, assignIndex: -1})
+			i += 2
+		case ch == '\'':
+			begin(true)
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j < 0 {
+				return nil, errorz.Errorf("unterminated single quote in: %v", s)
+			}
+			buf.WriteString(s[i+1 : i+1+j])
+			i += j + 2
+		case ch == '"':
+			begin(true)
+			j, err := readDoubleQuoted(s, i+1, buf)
+			if err != nil {
+				return nil, errorz.Wrap(err)
+			}
+			i = j
+		case ch == '\\':
+			begin(true)
+			if i+1 >= len(s) {
+				return nil, errorz.Errorf("trailing backslash in: %v", s)
+			}
+			if s[i+1] != '\n' {
+				buf.WriteByte(s[i+1])
+			}
+			i += 2
+		case strings.IndexByte("|;<>()$`*?[", ch) >= 0, cur == nil && (ch == '#' || ch == '~'):
+			return nil, errorz.Errorf("unsupported '%c' in: %v", ch, s)
+		default:
+			begin(false)
+			if ch == '=' && cur.assignIndex < 0 && !isQuoted && envNameRegexp.MatchString(buf.String()) {
+				cur.assignIndex = buf.Len()
+			}
+			buf.WriteByte(ch)
+			i++
+		}
+	}
+
+	flush()
+	return words, nil
+}
+
+// readDoubleQuoted reads a double-quoted string starting after the opening quote, and returns the index after the
+// closing quote.
+func readDoubleQuoted(s string, i int, buf *strings.Builder) (int, error) {
+	for i < len(s) {
+		switch ch := s[i]; ch {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+				if s[i+1] != '\n' {
+					buf.WriteByte(s[i+1])
+				}
+				i += 2
+			} else {
+				buf.WriteByte(ch)
+				i++
+			}
+		case '$', '`':
+			return 0, errorz.Errorf("unsupported '%c' in: %v", ch, s)
+		default:
+			buf.WriteByte(ch)
+			i++
+		}
+	}
+
+	return 0, errorz.Errorf("unterminated double quote in: %v", s)
+}
@@ -0,0 +1,139 @@
+package shellz_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/consolez"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+)
+
+type QuoteSuite struct {
+	// intentionally empty
+}
+
+func TestQuoteSuite(t *testing.T) {
+	fixturez.RunSuite(t, &QuoteSuite{})
+}
+
+func (*QuoteSuite) TestQuote(g *WithT) {
+	g.Expect(shellz.Quote("")).To(Equal("''"))
+	g.Expect(shellz.Quote("a-b_c.d/e:f,g=h@i%j+k")).To(Equal("a-b_c.d/e:f,g=h@i%j+k"))
+	g.Expect(shellz.Quote("a b")).To(Equal("'a b'"))
+	g.Expect(shellz.Quote("it's")).To(Equal(`'it'\''s'`))
+	g.Expect(shellz.Quote("$HOME")).To(Equal("'$HOME'"))
+	g.Expect(shellz.Quote("~")).To(Equal("'~'"))
+}
+
+func (*QuoteSuite) TestString(g *WithT) {
+	g.Expect(shellz.NewCommand("ls").String()).To(Equal("ls"))
+	g.Expect(shellz.NewCommand("echo", "a b", "it's", "").String()).To(Equal(`echo 'a b' 'it'\''s' ''`))
+	g.Expect(shellz.NewCommand("K=V").String()).To(Equal("'K=V'"))
+
+	g.Expect(shellz.NewCommand("go", "test", "-run=^Test$").
+		SetDir("/tmp/my dir").
+		SetEnv("GOOS", "linux").
+		SetEnv("CGO_ENABLED", "0").
+		SetEnv("FLAGS", "-a -b").
+		SetEnv("my-var", "v w").
+		String()).
+		To(Equal(`cd '/tmp/my dir' && CGO_ENABLED=0 FLAGS='-a -b' GOOS=linux env 'my-var=v w' go test '-run=^Test$'`))
+
+	g.Expect(shellz.NewCommand("printf", "a\nb").
+		Pipe(shellz.NewCommand("sort").SetDir("d")).
+		Pipe(shellz.NewCommand("head", "-n", "1").SetEnv("K", "V")).
+		String()).
+		To(Equal("printf 'a\nb' | (cd d && sort) | K=V head -n 1"))
+}
+
+func (*QuoteSuite) TestParseCommand(g *WithT) {
+	for _, c := range []*shellz.Command{
+		shellz.NewCommand("ls"),
+		shellz.NewCommand("echo", "a b", "it's", "", "$HOME", "~", "#", "&&", "a\"b", `a\b`, "a\nb", "ü"),
+		shellz.NewCommand("K=V", "K=V"),
+		shellz.NewCommand("cd", "d"),
+		shellz.NewCommand("go", "test", "-run=^Test$").
+			SetDir("/tmp/my dir").
+			SetEnv("GOOS", "linux").
+			SetEnv("FLAGS", "-a -b").
+			SetEnv("EMPTY", ""),
+		shellz.NewCommand("cmd", "p").
+			SetEnv("K", "V").
+			SetEnv("a-b", "v w").
+			SetEnv("c.d", ""),
+		shellz.NewCommand("env"),
+		shellz.NewCommand("env", "-i", "K=V", "cmd"),
+	} {
+		pc, err := shellz.ParseCommand(c.String())
+		g.Expect(err).To(Succeed())
+		g.Expect(pc.String()).To(Equal(c.String()))
+		g.Expect(pc.GetParams()).To(HaveExactElements(c.GetParams()))
+		g.Expect(pc.GetDir()).To(Equal(c.GetDir()))
+		g.Expect(pc.GetEnv()).To(Equal(c.GetEnv()))
+	}
+
+	c := shellz.MustParseCommand(`cd "my dir" && A=1 B="x y" C=a\ b 'D=2' echo "a \"b\" \$c \\d \e" a\'b "" `)
+	g.Expect(c.GetDir()).To(Equal("my dir"))
+	g.Expect(c.GetEnv()).To(Equal(map[string]string{"A": "1", "B": "x y", "C": "a b"}))
+	g.Expect(c.GetParams()).To(Equal([]string{`echo`, `a "b" $c \d \e`, `a'b`, ``}))
+	g.Expect(c.String()).To(Equal(`cd 'my dir' && A=1 B='x y' C='a b' 'D=2' echo 'a "b" $c \d \e' 'a'\''b' ''`))
+
+	c = shellz.MustParseCommand(`K=V env a-b=1 'c d=2' cmd p`)
+	g.Expect(c.GetEnv()).To(Equal(map[string]string{"K": "V", "a-b": "1", "c d": "2"}))
+	g.Expect(c.GetParams()).To(Equal([]string{"p"}))
+	g.Expect(c.String()).To(Equal(`K=V env a-b=1 'c d=2' cmd p`))
+
+	c = shellz.MustParseCommand(`env a-b=1`)
+	g.Expect(c.GetEnv()).To(BeEmpty())
+	g.Expect(c.GetParams()).To(Equal([]string{"a-b=1"}))
+	g.Expect(c.String()).To(Equal(`env a-b=1`))
+}
+
+func (*QuoteSuite) TestParseCommand_Errors(g *WithT) {
+	for s, msg := range map[string]string{
+		``:            "missing command in: ",
+		`K=V`:         "missing command in: K=V",
+		`cd d &&`:     "missing command in: cd d &&",
+		`a && b`:      "unexpected '&&' in: a && b",
+		`cd && && b`:  "unexpected '&&' in: cd && && b",
+		`a & b`:       "unsupported '&' in: a & b",
+		`a&&b`:        "unsupported '&' in: a&&b",
+		`a | b`:       "unsupported '|' in: a | b",
+		`a; b`:        "unsupported ';' in: a; b",
+		`a > b`:       "unsupported '>' in: a > b",
+		`echo $HOME`:  "unsupported '$' in: echo $HOME",
+		`echo "$H"`:   `unsupported '$' in: echo "$H"`,
+		"echo \"`\"":  "unsupported '`' in: echo \"`\"",
+		`ls *.go`:     "unsupported '*' in: ls *.go",
+		`ls ~`:        "unsupported '~' in: ls ~",
+		`ls #`:        "unsupported '#' in: ls #",
+		`echo 'a`:     "unterminated single quote in: echo 'a",
+		`echo "a`:     `unterminated double quote in: echo "a`,
+		`echo a\`:     `trailing backslash in: echo a\`,
+		`echo (a)`:    "unsupported '(' in: echo (a)",
+		`(cd d && a)`: "unsupported '(' in: (cd d && a)",
+	} {
+		_, err := shellz.ParseCommand(s)
+		g.Expect(err).To(MatchError(msg), s)
+	}
+
+	g.Expect(func() { shellz.MustParseCommand("") }).To(PanicWith(MatchError("missing command in: ")))
+}
+
+func (*QuoteSuite) TestEcho(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	shellz.NewCommand("echo", "a b").SetDir(".").SetEnv("K", "V").MustRun()
+	shellz.NewCommand("echo", "a b").SetDir(".").Pipe(shellz.NewCommand("cat").SetDir(".")).MustRun()
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal(fmt.Sprintf(
+		"%v \x1b[2mcd . && K=V\x1b[0m echo \x1b[2m'a b'\x1b[0m\na b\n"+
+			"%v \x1b[2m(cd . &&\x1b[0m echo \x1b[2m'a b') | (cd . && cat)\x1b[0m\na b\n",
+		consolez.IconRunner, consolez.IconRunner)))
+	g.Expect(errBuf).To(BeEmpty())
+}
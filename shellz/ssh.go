@@ -0,0 +1,456 @@
+package shellz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+var (
+	_ Executor = (*SSHExecutor)(nil)
+	_ error    = (*sshExitError)(nil)
+)
+
+var (
+	sshSignals = map[syscall.Signal]ssh.Signal{
+		syscall.SIGABRT: ssh.SIGABRT,
+		syscall.SIGALRM: ssh.SIGALRM,
+		syscall.SIGFPE:  ssh.SIGFPE,
+		syscall.SIGHUP:  ssh.SIGHUP,
+		syscall.SIGILL:  ssh.SIGILL,
+		syscall.SIGINT:  ssh.SIGINT,
+		syscall.SIGKILL: ssh.SIGKILL,
+		syscall.SIGPIPE: ssh.SIGPIPE,
+		syscall.SIGQUIT: ssh.SIGQUIT,
+		syscall.SIGSEGV: ssh.SIGSEGV,
+		syscall.SIGTERM: ssh.SIGTERM,
+		syscall.SIGUSR1: ssh.SIGUSR1,
+		syscall.SIGUSR2: ssh.SIGUSR2,
+	}
+)
+
+// SSHExecutor implements the Executor interface by running commands on a remote host over SSH, one session per
+// command. The command line is interpreted by the remote shell, and it is rendered like Command.String, so that the
+// dir and env of the command are applied remotely. The env mode (see Command.SetEnvMode) and the unset variables (see
+// Command.UnsetEnv) apply to the remote environment, using "env -i" and "env -u".
+//
+// Exit codes and standard error are reported like for local commands. Signals are sent as SSH "signal" requests, which
+// some servers ignore: SIGKILL also closes the session, so that the command is at least detached. Contexts and
+// timeouts are honored the same way, i.e. SIGTERM followed by SIGKILL after the grace period.
+//
+// When a command is started in the background (e.g. in a Pipeline), the *os.File standard output and error of the
+// command, other than os.Stdout and os.Stderr, are assumed to be pipes owned by the command (e.g. created by
+// "exec.Cmd.StdoutPipe"), and are closed once the session ends, as it happens when a real command exits. An *os.File
+// standard input is read from a duplicate, so that its owner can close it once the command starts, but like in
+// "os/exec" it is never closed. NetDialContext dials from the remote host, and Exec
+// is not supported.
+type SSHExecutor struct {
+	client   *ssh.Client
+	m        *sync.Mutex
+	sessions map[*exec.Cmd]*sshSession
+}
+
+// NewSSHExecutor initializes a new SSHExecutor using the given client.
+func NewSSHExecutor(client *ssh.Client) *SSHExecutor {
+	return &SSHExecutor{
+		client:   client,
+		m:        &sync.Mutex{},
+		sessions: make(map[*exec.Cmd]*sshSession),
+	}
+}
+
+// DialSSHExecutor connects to the given SSH server, and initializes a new SSHExecutor using the connection.
+func DialSSHExecutor(address string, config *ssh.ClientConfig) (*SSHExecutor, error) {
+	client, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, errorz.Wrap(err)
+	}
+
+	return NewSSHExecutor(client), nil
+}
+
+// MustDialSSHExecutor is like DialSSHExecutor but panics on error.
+func MustDialSSHExecutor(address string, config *ssh.ClientConfig) *SSHExecutor {
+	e, err := DialSSHExecutor(address, config)
+	errorz.MaybeMustWrap(err)
+	return e
+}
+
+// GetClient returns the SSH client.
+func (e *SSHExecutor) GetClient() *ssh.Client {
+	return e.client
+}
+
+// Close closes the SSH client.
+func (e *SSHExecutor) Close() error {
+	return errorz.MaybeWrap(e.client.Close())
+}
+
+// ExecCmdCombinedOutput implements the Executor interface.
+func (e *SSHExecutor) ExecCmdCombinedOutput(c *Command, cmd *exec.Cmd) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := &syncWriter{m: &sync.Mutex{}, w: buf}
+
+	s, err := e.start(c, cmd.Stdin, w, w, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.wait(nil)
+	return buf.Bytes(), err
+}
+
+// ExecCmdOutput implements the Executor interface. Like exec.Cmd.Output, if the standard error of the command is not
+// redirected, its tail is captured and attached to the error.
+func (e *SSHExecutor) ExecCmdOutput(c *Command, cmd *exec.Cmd) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	stderr := cmd.Stderr
+	var capturedStderr *ringBuffer
+
+	if stderr == nil {
+		capturedStderr = c.newCapture().stderr
+		stderr = capturedStderr
+	}
+
+	s, err := e.start(c, cmd.Stdin, buf, stderr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.wait(capturedStderr)
+	return buf.Bytes(), err
+}
+
+// ExecCmdRun implements the Executor interface.
+func (e *SSHExecutor) ExecCmdRun(c *Command, cmd *exec.Cmd) error {
+	s, err := e.start(c, cmd.Stdin, cmd.Stdout, cmd.Stderr, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.wait(nil)
+}
+
+// ExecCmdSignal implements the Executor interface. It returns an error matching "os.ErrProcessDone" if the command is
+// not running in the background.
+func (e *SSHExecutor) ExecCmdSignal(_ *Command, cmd *exec.Cmd, sig syscall.Signal) error {
+	e.m.Lock()
+	s, ok := e.sessions[cmd]
+	e.m.Unlock()
+
+	if !ok {
+		return errorz.Wrap(os.ErrProcessDone)
+	}
+
+	return s.signal(sig)
+}
+
+// ExecCmdStart implements the Executor interface.
+func (e *SSHExecutor) ExecCmdStart(c *Command, cmd *exec.Cmd) error {
+	files := make([]*os.File, 0, 3)
+
+	dup := func(f *os.File) *os.File {
+		df := DupFile(f)
+		files = append(files, df)
+		return df
+	}
+
+	own := func(f *os.File) *os.File {
+		df := dup(f)
+		_ = f.Close()
+		return df
+	}
+
+	stdin, stdout, stderr := cmd.Stdin, cmd.Stdout, cmd.Stderr
+
+	if f, ok := stdin.(*os.File); ok && f != os.Stdin {
+		stdin = dup(f)
+	}
+
+	if f, ok := stdout.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		stdout = own(f)
+	}
+
+	if f, ok := stderr.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		stderr = own(f)
+	}
+
+	s, err := e.start(c, stdin, stdout, stderr, files)
+	if err != nil {
+		return err
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.sessions[cmd] = s
+
+	return nil
+}
+
+// ExecCmdWait implements the Executor interface.
+func (e *SSHExecutor) ExecCmdWait(_ *Command, cmd *exec.Cmd) error {
+	e.m.Lock()
+	s, ok := e.sessions[cmd]
+	e.m.Unlock()
+
+	if !ok {
+		return errorz.Errorf("command not started: %v", cmd.Args[0])
+	}
+
+	err := s.wait(nil)
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	delete(e.sessions, cmd)
+
+	return err
+}
+
+// ExecLookPath implements the Executor interface. It returns the given file, which is looked up by the remote shell.
+func (e *SSHExecutor) ExecLookPath(_ *Command, file string) (string, error) {
+	return file, nil
+}
+
+// NetDialContext implements the Executor interface. The connection is made from the remote host.
+func (e *SSHExecutor) NetDialContext(ctx context.Context, _ *Command, network, address string) (net.Conn, error) {
+	return e.client.DialContext(ctx, network, address)
+}
+
+// OSChdir implements the Executor interface. It does nothing, as the dir is applied by the remote shell.
+func (e *SSHExecutor) OSChdir(_ *Command, _ string) error {
+	return nil
+}
+
+// SyscallExec implements the Executor interface. It always fails, as a remote command cannot replace the current
+// process.
+func (e *SSHExecutor) SyscallExec(_ *Command, argv0 string, _ []string, _ []string) error {
+	return errorz.Errorf("exec not supported over SSH: %v", argv0)
+}
+
+// start starts a session running the command. The given files are closed once the session ends.
+func (e *SSHExecutor) start(c *Command, stdin io.Reader, stdout, stderr io.Writer, files []*os.File) (*sshSession, error) {
+	closeFiles := func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}
+
+	session, err := e.client.NewSession()
+	if err != nil {
+		closeFiles()
+		return nil, errorz.Wrap(err)
+	}
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	var stdinW io.WriteCloser
+
+	if stdin != nil {
+		// Note: unlike setting ssh.Session.Stdin, this does not block until stdin is exhausted after the command exits.
+		if stdinW, err = session.StdinPipe(); err != nil {
+			_ = session.Close()
+			closeFiles()
+			return nil, errorz.Wrap(err)
+		}
+	}
+
+	if err := session.Start(renderSSHCommand(c)); err != nil {
+		_ = session.Close()
+		closeFiles()
+		return nil, errorz.Wrap(err)
+	}
+
+	if stdinW != nil {
+		go func() {
+			_, _ = io.Copy(stdinW, stdin)
+			_ = stdinW.Close()
+		}()
+	}
+
+	s := &sshSession{
+		session: session,
+		doneC:   make(chan struct{}),
+	}
+
+	go func() {
+		s.err = session.Wait()
+		_ = session.Close()
+		closeFiles()
+		close(s.doneC)
+	}()
+
+	if c.ctx != nil || c.timeout > 0 {
+		go s.watch(c)
+	}
+
+	return s, nil
+}
+
+// sshSession describes a command running in an SSH session.
+type sshSession struct {
+	session *ssh.Session
+	doneC   chan struct{}
+	err     error
+}
+
+// wait waits for the session to end, and converts the error. The captured standard error (if any) is attached to the
+// resulting *sshExitError.
+func (s *sshSession) wait(capturedStderr *ringBuffer) error {
+	<-s.doneC
+
+	if eErr, ok := errorz.As[*ssh.ExitError](s.err); ok {
+		return &sshExitError{
+			err:    eErr,
+			stderr: []byte(capturedStderr.String()),
+		}
+	}
+
+	return errorz.MaybeWrap(s.err)
+}
+
+// signal sends a signal to the command. It returns an error matching "os.ErrProcessDone" if the session has ended.
+func (s *sshSession) signal(sig syscall.Signal) error {
+	select {
+	case <-s.doneC:
+		return errorz.Wrap(os.ErrProcessDone)
+	default:
+	}
+
+	name, ok := sshSignals[sig]
+	if !ok {
+		return errorz.Errorf("unsupported signal: %v", sig)
+	}
+
+	if err := s.session.Signal(name); err != nil {
+		return errorz.Wrap(err)
+	}
+
+	if sig == syscall.SIGKILL {
+		_ = s.session.Close()
+	}
+
+	return nil
+}
+
+// watch terminates the command when its context is done, like newCmd does for local commands.
+func (s *sshSession) watch(c *Command) {
+	ctx, cancel := c.newContext()
+	defer cancel()
+
+	select {
+	case <-s.doneC:
+		return
+	case <-ctx.Done():
+	}
+
+	if err := s.signal(syscall.SIGTERM); err != nil {
+		return
+	}
+
+	t := time.NewTimer(c.gracePeriod)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		_ = s.signal(syscall.SIGKILL)
+	case <-s.doneC:
+	}
+}
+
+// sshExitError is returned by an SSHExecutor in place of *exec.ExitError. Like *exec.ExitError, it provides the exit
+// code and captured standard error to the resulting *ExecutionError.
+type sshExitError struct {
+	err    *ssh.ExitError
+	stderr []byte
+}
+
+// Error implements the error interface. The message matches the one of *exec.ExitError.
+func (e *sshExitError) Error() string {
+	if e.err.Signal() != "" {
+		return fmt.Sprintf("signal: %v", e.err.Signal())
+	}
+
+	return fmt.Sprintf("exit status %v", e.err.ExitStatus())
+}
+
+// ExitCode returns the exit code, or -1 if the command was terminated by a signal.
+func (e *sshExitError) ExitCode() int {
+	if e.err.Signal() != "" {
+		return -1
+	}
+
+	return e.err.ExitStatus()
+}
+
+// Unwrap implements the errorz.UnwrapSingle interface.
+func (e *sshExitError) Unwrap() error {
+	return e.err
+}
+
+// renderSSHCommand renders the command line run by an SSHExecutor, see Command.String. If the command has an env mode
+// other than EnvModeInherit or unset variables, the env is passed through "env" instead. Allowlisted variables are
+// passed through from the remote environment if set.
+func renderSSHCommand(c *Command) string {
+	if c.envMode == EnvModeInherit && len(c.envUnset) == 0 {
+		return c.String()
+	}
+
+	words := []string{"env"}
+
+	if c.envMode == EnvModeInherit {
+		for _, k := range c.envUnset {
+			words = append(words, "-u", Quote(k))
+		}
+	} else {
+		words = append(words, "-i")
+	}
+
+	if c.envMode == EnvModeAllowlist {
+		for _, k := range c.envAllow {
+			if envNameRegexp.MatchString(k) && !slices.Contains(c.envUnset, k) {
+				words = append(words, fmt.Sprintf(`${%v+"%v=$%v"}`, k, k, k))
+			}
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(c.env)) {
+		words = append(words, Quote(k+"="+c.env[k]))
+	}
+
+	_, cmdWords := renderCommandParts("", nil, c.cmd, c.params)
+	words = append(words, cmdWords...)
+
+	if c.dir != "" {
+		return "cd " + Quote(c.dir) + " && " + strings.Join(words, " ")
+	}
+
+	return strings.Join(words, " ")
+}
+
+// syncWriter serializes writes to the wrapped writer.
+type syncWriter struct {
+	m *sync.Mutex
+	w io.Writer
+}
+
+// Write implements the io.Writer interface.
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,396 @@
+package shellz_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/consolez"
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/filez"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
+)
+
+type SSHSuite struct {
+	// intentionally empty
+}
+
+func TestSSHSuite(t *testing.T) {
+	fixturez.RunSuite(t, &SSHSuite{})
+}
+
+func newSSHExecutor(g *WithT) (*tshellz.SSHServer, *shellz.SSHExecutor, func()) {
+	s := tshellz.MustNewSSHServer()
+	e := shellz.MustDialSSHExecutor(s.GetAddress(), s.GetClientConfig())
+
+	return s, e, func() {
+		g.Expect(e.Close()).To(Succeed())
+		g.Expect(s.Close()).To(Succeed())
+	}
+}
+
+func (*SSHSuite) TestRun(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	s, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	g.Expect(e.GetClient()).ToNot(BeNil())
+
+	shellz.NewCommand("sh", "-c", "echo out; echo err >&2").SetExecutor(e).MustRun()
+	g.Expect(s.GetHistory()).To(Equal([]string{"sh -c 'echo out; echo err >&2'"}))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal(fmt.Sprintf("%v sh \x1b[2m-c 'echo out; echo err >&2'\x1b[0m\nout\n", consolez.IconRunner)))
+	g.Expect(errBuf).To(Equal("err\n"))
+}
+
+func (*SSHSuite) TestRun_Error(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	err := shellz.NewCommand("sh", "-c", "echo err >&2; exit 3").SetEcho(false).SetExecutor(e).Run()
+	g.Expect(err).To(MatchError("execution error: sh -c 'echo err >&2; exit 3': exit status 3"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(3))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err\n"))
+
+	err = shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8").SetEcho(false).SetExecutor(e).Run()
+	g.Expect(err).To(MatchError("execution error: cae0e988-f55b-4803-a471-a877b686d1a8: exit status 127"))
+
+	_, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(errBuf).To(HavePrefix("err\n"))
+	g.Expect(errBuf).To(ContainSubstring("cae0e988-f55b-4803-a471-a877b686d1a8"))
+}
+
+func (*SSHSuite) TestDirEnv(g *WithT) {
+	s, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	dirPath := filepath.Join(filez.MustCreateTempDir(), "my dir")
+	defer func() { _ = os.RemoveAll(filepath.Dir(dirPath)) }()
+	g.Expect(os.Mkdir(dirPath, 0777)).To(Succeed())
+
+	c1 := shellz.NewCommand("sh", "-c", `pwd; echo "$A $B"`).
+		SetDir(dirPath).
+		SetEnv("A", "1").
+		SetEnv("B", "x 'y'").
+		SetExecutor(e)
+
+	c2 := shellz.NewCommand("printenv", "my-var").
+		SetEnv("my-var", "v w").
+		SetExecutor(e)
+
+	g.Expect(c1.MustOutputString(false)).To(Equal(dirPath + "\n1 x 'y'\n"))
+	g.Expect(c2.MustOutputString(false)).To(Equal("v w\n"))
+	g.Expect(s.GetHistory()).To(Equal([]string{c1.String(), c2.String()}))
+}
+
+func (*SSHSuite) TestEnvMode(g *WithT) {
+	s, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	g.Expect(shellz.NewCommand("sh", "-c", "echo ${HOME-unset} ${K-unset}").
+		SetEnv("K", "V").
+		UnsetEnv("HOME").
+		SetExecutor(e).
+		MustOutputString(false)).
+		To(Equal("unset V\n"))
+
+	g.Expect(shellz.NewCommand("env").
+		SetEnv("K", "V").
+		SetEnvMode(shellz.EnvModeClean).
+		SetExecutor(e).
+		MustOutputString(false)).
+		To(Equal("K=V\n"))
+
+	g.Expect(shellz.NewCommand("env").
+		SetEnv("K", "V").
+		SetEnvMode(shellz.EnvModeAllowlist).
+		AddEnvAllowlist("PATH", "HOME", "SHELLZ_MISSING_VAR", "my-var").
+		UnsetEnv("HOME").
+		SetExecutor(e).
+		MustOutputString(false)).
+		To(Equal(fmt.Sprintf("PATH=%v\nK=V\n", os.Getenv("PATH"))))
+
+	g.Expect(s.GetHistory()).To(Equal([]string{
+		"env -u HOME K=V sh -c 'echo ${HOME-unset} ${K-unset}'",
+		"env -i K=V env",
+		`env -i ${PATH+"PATH=$PATH"} ${SHELLZ_MISSING_VAR+"SHELLZ_MISSING_VAR=$SHELLZ_MISSING_VAR"} K=V env`,
+	}))
+
+	g.Expect(shellz.NewCommand("pwd").
+		SetDir("/").
+		SetEnvMode(shellz.EnvModeClean).
+		SetExecutor(e).
+		MustOutputString(false)).
+		To(Equal("/\n"))
+}
+
+func (*SSHSuite) TestStdin(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	g.Expect(shellz.NewCommand("cat").
+		SetIn(strings.NewReader("a\nb\n")).
+		SetExecutor(e).
+		MustOutputString(false)).
+		To(Equal("a\nb\n"))
+
+	inR, inW := io.Pipe()
+	defer func() { _ = inW.Close() }()
+
+	g.Expect(shellz.NewCommand("echo", "ignored").
+		SetIn(inR).
+		SetExecutor(e).
+		MustOutputString(false)).
+		To(Equal("ignored\n"))
+
+	f, err := os.CreateTemp("", "shellz-")
+	g.Expect(err).To(Succeed())
+	defer func() { _ = os.Remove(f.Name()) }()
+	defer func() { _ = f.Close() }()
+
+	_, err = f.WriteString("a\nb\n")
+	g.Expect(err).To(Succeed())
+	_, err = f.Seek(0, io.SeekStart)
+	g.Expect(err).To(Succeed())
+
+	lines := make([]string, 0)
+	g.Expect(shellz.NewCommand("cat").
+		SetIn(f).
+		SetEcho(false).
+		SetExecutor(e).
+		Lines(func(line string) { lines = append(lines, line) })).
+		To(Succeed())
+	g.Expect(lines).To(Equal([]string{"a", "b"}))
+
+	_, err = f.Seek(0, io.SeekStart)
+	g.Expect(err).To(Succeed())
+	buf, err := io.ReadAll(f)
+	g.Expect(err).To(Succeed())
+	g.Expect(string(buf)).To(Equal("a\nb\n"))
+}
+
+func (*SSHSuite) TestOutput_Error(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	c := shellz.NewCommand("sh", "-c", "echo out; echo err >&2; exit 3").SetExecutor(e)
+
+	out, err := c.Output(false)
+	g.Expect(out).To(BeNil())
+	g.Expect(err).To(MatchError("execution error: sh -c 'echo out; echo err >&2; exit 3': exit status 3"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(3))
+	g.Expect(eErr.GetCapturedStdout()).To(BeEmpty())
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err\n"))
+
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	_, err = c.SetCaptureStdout(true).Output(true)
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStdout()).To(Equal("out\n"))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err\n"))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(BeEmpty())
+	g.Expect(errBuf).To(Equal("err\n"))
+}
+
+func (*SSHSuite) TestCombinedOutput(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	g.Expect(shellz.NewCommand("sh", "-c", "echo out; sleep 0.1; echo err >&2").
+		SetExecutor(e).
+		MustCombinedOutputString()).
+		To(Equal("out\nerr\n"))
+
+	_, err := shellz.NewCommand("sh", "-c", "echo out; sleep 0.1; echo err >&2; exit 2").
+		SetExecutor(e).
+		CombinedOutput()
+	g.Expect(err).To(MatchError("execution error: sh -c 'echo out; sleep 0.1; echo err >&2; exit 2': exit status 2"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(2))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("out\nerr\n"))
+}
+
+func (*SSHSuite) TestLineEvents(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	lines := make([]string, 0)
+
+	g.Expect(shellz.NewCommand("sh", "-c", "echo o1; echo e1 >&2; echo o2").
+		SetEcho(false).
+		SetExecutor(e).
+		LineEvents(func(e *shellz.LineEvent) {
+			lines = append(lines, fmt.Sprintf("%v: %v", e.Stream, e.Text))
+		})).To(Succeed())
+	g.Expect(lines).To(ConsistOf("stdout: o1", "stderr: e1", "stdout: o2"))
+
+	lines = make([]string, 0)
+
+	err := shellz.NewCommand("sh", "-c", "echo out; echo err >&2; exit 4").
+		SetEcho(false).
+		SetExecutor(e).
+		Lines(func(line string) {
+			lines = append(lines, line)
+		})
+	g.Expect(err).To(MatchError("execution error: sh -c 'echo out; echo err >&2; exit 4': exit status 4"))
+	g.Expect(lines).To(ConsistOf("out", "err"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(4))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err\n"))
+}
+
+func (*SSHSuite) TestPipeline(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	g.Expect(shellz.NewCommand("printf", "b\\na\\nc\\n").SetExecutor(e).
+		Pipe(shellz.NewCommand("sort")).
+		Pipe(shellz.NewCommand("head", "-n", "2").SetExecutor(e)).
+		SetEcho(false).
+		MustOutputString(false)).
+		To(Equal("a\nb\n"))
+
+	err := shellz.NewCommand("echo", "in").SetExecutor(e).
+		Pipe(shellz.NewCommand("sh", "-c", "cat; exit 4").SetExecutor(e)).
+		SetEcho(false).
+		Run()
+	g.Expect(err).To(MatchError("execution error: pipeline stage 2 of 2: sh -c 'cat; exit 4': exit status 4"))
+}
+
+func (*SSHSuite) TestStart(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	p := shellz.NewCommand("sh", "-c", "echo ready; sleep 10").
+		SetEcho(false).
+		SetExecutor(e).
+		MustStart()
+
+	g.Expect(p.GetPid()).To(Equal(-1))
+	g.Expect(p.MustWaitForLine(context.Background(), regexp.MustCompile("^ready$"))).To(Equal("ready"))
+	g.Expect(p.Stop()).To(Succeed())
+	g.Expect(p.GetExitCode()).To(Equal(-1))
+	g.Expect(p.Wait()).To(MatchError("execution error: sh -c 'echo ready; sleep 10': signal: TERM"))
+	g.Expect(errors.Is(p.Signal(syscall.SIGINT), os.ErrProcessDone)).To(BeTrue())
+
+	p = shellz.NewCommand("sh", "-c", "trap '' TERM; echo ready; sleep 10").
+		SetEcho(false).
+		SetGracePeriod(100 * time.Millisecond).
+		SetExecutor(e).
+		MustStart()
+
+	p.MustWaitForLine(context.Background(), regexp.MustCompile("^ready$"))
+	g.Expect(p.Signal(syscall.Signal(0))).To(MatchError("unsupported signal: signal 0"))
+	g.Expect(p.Stop()).To(Succeed())
+	g.Expect(p.Wait()).To(HaveOccurred())
+}
+
+func (*SSHSuite) TestTimeout(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	start := time.Now()
+
+	err := shellz.NewCommand("sleep", "10").
+		SetTimeout(100 * time.Millisecond).
+		SetEcho(false).
+		SetExecutor(e).
+		Run()
+	g.Expect(err).To(MatchError("execution error: sleep 10: timed out: signal: TERM"))
+	g.Expect(errors.Is(err, shellz.ErrTimeout)).To(BeTrue())
+	g.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err = shellz.NewCommand("sh", "-c", "trap '' TERM; sleep 10").
+		SetContext(ctx).
+		SetGracePeriod(100 * time.Millisecond).
+		SetExecutor(e).
+		Output(false)
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	g.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+}
+
+func (*SSHSuite) TestNetDialContext(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).To(Succeed())
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			_, _ = io.Copy(conn, conn)
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := e.NetDialContext(context.Background(), nil, "tcp", listener.Addr().String())
+	g.Expect(err).To(Succeed())
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte("ping"))
+	g.Expect(err).To(Succeed())
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	g.Expect(err).To(Succeed())
+	g.Expect(string(buf)).To(Equal("ping"))
+
+	_, err = e.NetDialContext(context.Background(), nil, "tcp", "127.0.0.1:1")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func (*SSHSuite) TestExec(g *WithT) {
+	_, e, closeAll := newSSHExecutor(g)
+	defer closeAll()
+
+	g.Expect(shellz.NewCommand("ls").SetDir("/").SetEcho(false).SetExecutor(e).Exec()).
+		To(MatchError("execution error: cd / && ls: exec not supported over SSH: ls"))
+}
+
+func (*SSHSuite) TestDialSSHExecutor_Error(g *WithT) {
+	s := tshellz.MustNewSSHServer()
+	address := s.GetAddress()
+	g.Expect(s.Close()).To(Succeed())
+
+	_, err := shellz.DialSSHExecutor(address, s.GetClientConfig())
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(func() { shellz.MustDialSSHExecutor(address, s.GetClientConfig()) }).To(Panic())
+}
@@ -0,0 +1,149 @@
+package shellz
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+// Stream identifies an output stream of a command.
+type Stream string
+
+// Known streams.
+const (
+	StreamStdout Stream = "stdout"
+	StreamStderr Stream = "stderr"
+)
+
+// LineEvent describes a line of output of a command.
+type LineEvent struct {
+	Stream Stream
+	Text   string
+	Time   time.Time
+}
+
+// SplitLineEvents returns a function that passes each event to the given function for its stream. Either function
+// can be nil, in which case the events for that stream are discarded.
+func SplitLineEvents(stdoutFunc, stderrFunc func(*LineEvent)) func(*LineEvent) {
+	return func(e *LineEvent) {
+		switch {
+		case e.Stream == StreamStdout && stdoutFunc != nil:
+			stdoutFunc(e)
+		case e.Stream == StreamStderr && stderrFunc != nil:
+			stderrFunc(e)
+		}
+	}
+}
+
+// SendLineEvents returns a function that sends each event to the given channel, blocking until it is received.
+// The channel is not closed when the command exits.
+func SendLineEvents(eventC chan<- *LineEvent) func(*LineEvent) {
+	return func(e *LineEvent) {
+		eventC <- e
+	}
+}
+
+// LineEvents runs the command and passes each line of its standard output and error to the given function, tagged
+// with the stream it came from and the time it was read. If the command fails, the tail of its output is attached to the
+// returned *ExecutionError (see Command.SetCaptureSize).
+//
+// The function is never called concurrently, and all calls complete before LineEvents returns. Lines of the same stream
+// are delivered in the order they are written, and event times are non-decreasing. Lines of different streams are
+// delivered in the order they are read, which may differ from the order they are written, as each stream is buffered
+// independently.
+func (c *Command) LineEvents(eventFunc func(*LineEvent)) error {
+	c.maybeEcho(true)
+	cmd, ctx, done := c.newCmd()
+	defer done()
+
+	outR, outW, err := os.Pipe()
+	errorz.MaybeMustWrap(err)
+	defer func() { _ = outR.Close() }()
+
+	errR, errW, err := os.Pipe()
+	errorz.MaybeMustWrap(err)
+	defer func() { _ = errR.Close() }()
+
+	cmd.Stdout = outW
+	cmd.Stderr = errW
+
+	m := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	cp := c.newCapture()
+
+	newLineFunc := func(stream Stream) func(string) {
+		return func(line string) {
+			m.Lock()
+			defer m.Unlock()
+
+			if stream == StreamStderr {
+				_, _ = cp.stderr.Write([]byte(line + "\n"))
+			} else {
+				_, _ = cp.stdout.Write([]byte(line + "\n"))
+			}
+
+			eventFunc(&LineEvent{
+				Stream: stream,
+				Text:   line,
+				Time:   time.Now(),
+			})
+		}
+	}
+
+	go handleLines(wg, outR, newLineFunc(StreamStdout))
+	go handleLines(wg, errR, newLineFunc(StreamStderr))
+
+	err = c.executor.ExecCmdStart(c, cmd)
+	_ = outW.Close()
+	_ = errW.Close()
+
+	if err != nil {
+		waitLines(wg, c.gracePeriod, outR, errR)
+		return cp.attach(newExecutionError(ctx, err, c))
+	}
+
+	err = c.executor.ExecCmdWait(c, cmd)
+	waitLines(wg, c.gracePeriod, outR, errR)
+
+	if err != nil {
+		return cp.attach(newExecutionError(ctx, err, c))
+	}
+
+	return nil
+}
+
+// MustLineEvents is like LineEvents but panics on error.
+func (c *Command) MustLineEvents(eventFunc func(*LineEvent)) {
+	errorz.MaybeMustWrap(c.LineEvents(eventFunc))
+}
+
+// waitLines waits for all the lines to be handled. If the output is still open after the grace period (e.g. because a
+// child of the command inherited it), its read ends are closed, similarly to exec.Cmd.WaitDelay.
+func waitLines(wg *sync.WaitGroup, gracePeriod time.Duration, rs ...io.Closer) {
+	doneC := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(doneC)
+	}()
+
+	t := time.NewTimer(gracePeriod)
+	defer t.Stop()
+
+	select {
+	case <-doneC:
+		return
+	case <-t.C:
+	}
+
+	for _, r := range rs {
+		_ = r.Close()
+	}
+
+	<-doneC
+}
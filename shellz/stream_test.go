@@ -0,0 +1,144 @@
+package shellz_test
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ibrt/golang-lib/consolez"
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
+)
+
+type StreamSuite struct {
+	// intentionally empty
+}
+
+func TestStreamSuite(t *testing.T) {
+	fixturez.RunSuite(t, &StreamSuite{})
+}
+
+func (*StreamSuite) TestLineEvents_Success(g *WithT) {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	events := make([]*shellz.LineEvent, 0)
+
+	g.Expect(shellz.NewCommand("sh", "-c", "echo o1; echo e1 >&2; echo o2; echo e2 >&2").
+		LineEvents(func(e *shellz.LineEvent) {
+			events = append(events, e)
+		})).To(Succeed())
+
+	g.Expect(events).To(HaveLen(4))
+
+	for i := 1; i < len(events); i++ {
+		g.Expect(events[i].Time).ToNot(BeTemporally("<", events[i-1].Time))
+	}
+
+	stdoutLines := make([]string, 0)
+	stderrLines := make([]string, 0)
+
+	split := shellz.SplitLineEvents(
+		func(e *shellz.LineEvent) { stdoutLines = append(stdoutLines, e.Text) },
+		func(e *shellz.LineEvent) { stderrLines = append(stderrLines, e.Text) })
+
+	for _, e := range events {
+		split(e)
+	}
+
+	g.Expect(stdoutLines).To(Equal([]string{"o1", "o2"}))
+	g.Expect(stderrLines).To(Equal([]string{"e1", "e2"}))
+
+	g.Expect(func() {
+		shellz.SplitLineEvents(nil, nil)(&shellz.LineEvent{Stream: shellz.StreamStdout})
+	}).ToNot(Panic())
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(HavePrefix(fmt.Sprintf("%v sh ", consolez.IconRunner)))
+	g.Expect(errBuf).To(BeEmpty())
+}
+
+func (*StreamSuite) TestLineEvents_Channel(g *WithT) {
+	eventC := make(chan *shellz.LineEvent)
+	errC := make(chan error, 1)
+
+	go func() {
+		errC <- shellz.NewCommand("sh", "-c", "echo out; echo err >&2").
+			SetEcho(false).
+			LineEvents(shellz.SendLineEvents(eventC))
+		close(eventC)
+	}()
+
+	lines := make([]string, 0)
+	for e := range eventC {
+		lines = append(lines, fmt.Sprintf("%v: %v", e.Stream, e.Text))
+	}
+
+	g.Expect(<-errC).To(Succeed())
+	g.Expect(lines).To(ConsistOf("stdout: out", "stderr: err"))
+}
+
+func (*StreamSuite) TestLineEvents_Error(g *WithT) {
+	err := shellz.NewCommand("sh", "-c", "echo out; echo err1 >&2; echo err2 >&2; exit 3").
+		SetEcho(false).
+		LineEvents(func(*shellz.LineEvent) {})
+	g.Expect(err).To(MatchError("execution error: sh -c 'echo out; echo err1 >&2; echo err2 >&2; exit 3': exit status 3"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(3))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err1\nerr2\n"))
+
+	g.Expect(func() {
+		shellz.NewCommand("cae0e988-f55b-4803-a471-a877b686d1a8").
+			SetEcho(false).
+			MustLineEvents(func(*shellz.LineEvent) {})
+	}).To(Panic())
+}
+
+func (*StreamSuite) TestLineEvents_Error_Start(g *WithT, ctrl *gomock.Controller) {
+	m := tshellz.NewMockExecutor(ctrl)
+
+	m.EXPECT().ExecCmdStart(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *shellz.Command, cmd *exec.Cmd) error {
+		_, _ = io.WriteString(cmd.Stderr, "err\n")
+		return errorz.Errorf("start error")
+	})
+
+	lines := make([]string, 0)
+
+	err := shellz.NewCommand("cmd").
+		SetEcho(false).
+		SetExecutor(m).
+		LineEvents(func(e *shellz.LineEvent) {
+			time.Sleep(10 * time.Millisecond)
+			lines = append(lines, e.Text)
+		})
+	g.Expect(err).To(MatchError("execution error: cmd: start error"))
+	g.Expect(lines).To(Equal([]string{"err"}))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("err\n"))
+}
+
+func (*StreamSuite) TestLineEvents_Error_Tail(g *WithT) {
+	err := shellz.NewCommand("sh", "-c", "for i in $(seq 1 5000); do echo line-$i >&2; done; exit 1").
+		SetEcho(false).
+		LineEvents(func(*shellz.LineEvent) {})
+	g.Expect(err).To(MatchError("execution error: sh -c 'for i in $(seq 1 5000); do echo line-$i >&2; done; exit 1': exit status 1"))
+
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetCapturedStderr()).To(HaveLen(shellz.DefaultCaptureSize))
+	g.Expect(eErr.GetCapturedStderr()).To(HaveSuffix("line-4999\nline-5000\n"))
+	g.Expect(eErr.GetCapturedStderr()).ToNot(ContainSubstring("line-1\n"))
+	g.Expect(strings.Count(eErr.GetCapturedStderr(), "\n")).To(BeNumerically("<", 5000))
+}
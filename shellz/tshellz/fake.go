@@ -0,0 +1,312 @@
+package tshellz
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/shellz"
+)
+
+var (
+	_ shellz.Executor = (*FakeExecutor)(nil)
+)
+
+// ModeExec is the mode of the commands exec'd through a FakeExecutor (see shellz.Command.Exec).
+const ModeExec = "SyscallExec"
+
+// FakeCall describes a command handled by a FakeExecutor.
+// Standard input is read unless it is an *os.File or the command is started in the background.
+type FakeCall struct {
+	Mode    string
+	Command *shellz.Command
+	Cmd     string
+	Params  []string
+	Dir     string
+	Env     map[string]string
+	Stdin   string
+}
+
+// GetArgs returns the command followed by the params.
+func (c *FakeCall) GetArgs() []string {
+	return append([]string{c.Cmd}, c.Params...)
+}
+
+// FakeResult describes the simulated outcome of a command. If Err is set, it is returned as is (e.g. to simulate a
+// command failing to start). Otherwise, a non-zero ExitCode is returned as a *shellz.SimulatedExitError.
+type FakeResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+}
+
+// FakeMatcher decides whether a FakeExecutor handler applies to a call.
+type FakeMatcher func(call *FakeCall) bool
+
+// FakeHandler computes the simulated outcome of a call. It may also have side effects, e.g. writing files.
+type FakeHandler func(call *FakeCall) *FakeResult
+
+// MatchCommand returns a FakeMatcher that matches calls with the given command name.
+func MatchCommand(cmd string) FakeMatcher {
+	return func(call *FakeCall) bool {
+		return call.Cmd == cmd
+	}
+}
+
+// MatchArgs returns a FakeMatcher that matches calls whose command and params, joined by spaces, match the regexp.
+func MatchArgs(re *regexp.Regexp) FakeMatcher {
+	return func(call *FakeCall) bool {
+		return re.MatchString(strings.Join(call.GetArgs(), " "))
+	}
+}
+
+// MatchAny returns a FakeMatcher that matches all calls.
+func MatchAny() FakeMatcher {
+	return func(_ *FakeCall) bool {
+		return true
+	}
+}
+
+// Returns returns a FakeHandler that always returns the given outcome.
+func Returns(stdout, stderr string, exitCode int) FakeHandler {
+	return func(_ *FakeCall) *FakeResult {
+		return &FakeResult{
+			Stdout:   stdout,
+			Stderr:   stderr,
+			ExitCode: exitCode,
+		}
+	}
+}
+
+type fakeHandlerEntry struct {
+	matcher FakeMatcher
+	handler FakeHandler
+}
+
+type fakeStart struct {
+	err  error
+	wait []func()
+}
+
+// FakeExecutor is an in-memory Executor that does not run anything: each command is served by the first registered
+// handler that matches it, in order of registration, and recorded in the history. Commands that no handler matches
+// fail with an error.
+//
+// Like *exec.ExitError, the *shellz.SimulatedExitError returned on failure carries the simulated standard error only
+// when it is not written elsewhere, i.e. in "Output(false)".
+//
+// When a command is started in the background, its output is written asynchronously. Output writers which are
+// *os.File, other than os.Stdout and os.Stderr, are assumed to be pipes owned by the command (e.g. created by
+// "exec.Cmd.StdoutPipe"), and are closed once the output has been written, as it happens when a real command exits.
+type FakeExecutor struct {
+	m        *sync.Mutex
+	handlers []*fakeHandlerEntry
+	history  []*FakeCall
+	started  map[*exec.Cmd]*fakeStart
+}
+
+// NewFakeExecutor initializes a new FakeExecutor.
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{
+		m:        &sync.Mutex{},
+		handlers: make([]*fakeHandlerEntry, 0),
+		history:  make([]*FakeCall, 0),
+		started:  make(map[*exec.Cmd]*fakeStart),
+	}
+}
+
+// Handle registers a handler.
+func (e *FakeExecutor) Handle(matcher FakeMatcher, handler FakeHandler) *FakeExecutor {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.handlers = append(e.handlers, &fakeHandlerEntry{
+		matcher: matcher,
+		handler: handler,
+	})
+
+	return e
+}
+
+// GetHistory returns the calls handled so far, including the unexpected ones.
+func (e *FakeExecutor) GetHistory() []*FakeCall {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return slices.Clone(e.history)
+}
+
+// ExecCmdCombinedOutput implements the shellz.Executor interface.
+func (e *FakeExecutor) ExecCmdCombinedOutput(c *shellz.Command, cmd *exec.Cmd) ([]byte, error) {
+	r, err := e.handle(ModeCombinedOutput, c, cmd.Args[0], cmd.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(r.Stdout + r.Stderr), r.getError(false)
+}
+
+// ExecCmdOutput implements the shellz.Executor interface.
+func (e *FakeExecutor) ExecCmdOutput(c *shellz.Command, cmd *exec.Cmd) ([]byte, error) {
+	r, err := e.handle(ModeOutput, c, cmd.Args[0], cmd.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, r.Stderr)
+	}
+
+	return []byte(r.Stdout), r.getError(cmd.Stderr == nil)
+}
+
+// ExecCmdRun implements the shellz.Executor interface.
+func (e *FakeExecutor) ExecCmdRun(c *shellz.Command, cmd *exec.Cmd) error {
+	r, err := e.handle(ModeRun, c, cmd.Args[0], cmd.Stdin)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Stdout != nil {
+		_, _ = io.WriteString(cmd.Stdout, r.Stdout)
+	}
+
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, r.Stderr)
+	}
+
+	return r.getError(false)
+}
+
+// ExecCmdSignal implements the shellz.Executor interface. It does nothing.
+func (e *FakeExecutor) ExecCmdSignal(_ *shellz.Command, _ *exec.Cmd, _ syscall.Signal) error {
+	return nil
+}
+
+// ExecCmdStart implements the shellz.Executor interface.
+func (e *FakeExecutor) ExecCmdStart(c *shellz.Command, cmd *exec.Cmd) error {
+	r, err := e.handle(ModeStart, c, cmd.Args[0], cmd.Stdin)
+	if err != nil {
+		return err
+	}
+
+	if r.Err != nil {
+		return r.Err
+	}
+
+	s := &fakeStart{
+		err: r.getError(false),
+		wait: []func(){
+			writeStartOutput(cmd.Stdout, r.Stdout),
+			writeStartOutput(cmd.Stderr, r.Stderr),
+		},
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.started[cmd] = s
+
+	return nil
+}
+
+// ExecCmdWait implements the shellz.Executor interface.
+func (e *FakeExecutor) ExecCmdWait(_ *shellz.Command, cmd *exec.Cmd) error {
+	e.m.Lock()
+	s, ok := e.started[cmd]
+	delete(e.started, cmd)
+	e.m.Unlock()
+
+	if !ok {
+		return errorz.Errorf("command not started: %v", cmd.Args[0])
+	}
+
+	for _, f := range s.wait {
+		f()
+	}
+
+	return s.err
+}
+
+// ExecLookPath implements the shellz.Executor interface. It returns the given file.
+func (e *FakeExecutor) ExecLookPath(_ *shellz.Command, file string) (string, error) {
+	return file, nil
+}
+
+// NetDialContext implements the shellz.Executor interface. It always succeeds, returning one end of a "net.Pipe".
+func (e *FakeExecutor) NetDialContext(_ context.Context, _ *shellz.Command, _, _ string) (net.Conn, error) {
+	c1, c2 := net.Pipe()
+	_ = c2.Close()
+	return c1, nil
+}
+
+// OSChdir implements the shellz.Executor interface. It does nothing.
+func (e *FakeExecutor) OSChdir(_ *shellz.Command, _ string) error {
+	return nil
+}
+
+// SyscallExec implements the shellz.Executor interface. The command is handled like the others (with ModeExec), and
+// only the resulting error is returned.
+func (e *FakeExecutor) SyscallExec(c *shellz.Command, _ string, argv []string, _ []string) error {
+	r, err := e.handle(ModeExec, c, argv[0], nil)
+	if err != nil {
+		return err
+	}
+
+	return r.getError(false)
+}
+
+func (e *FakeExecutor) handle(mode string, c *shellz.Command, name string, stdin io.Reader) (*FakeResult, error) {
+	call := &FakeCall{
+		Mode:    mode,
+		Command: c,
+		Cmd:     name,
+		Params:  c.GetParams(),
+		Dir:     c.GetDir(),
+		Env:     c.GetEnv(),
+	}
+
+	if _, ok := stdin.(*os.File); stdin != nil && !ok && mode != ModeStart {
+		buf, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, errorz.Wrap(err)
+		}
+		call.Stdin = string(buf)
+	}
+
+	e.m.Lock()
+	e.history = append(e.history, call)
+	handlers := slices.Clone(e.handlers)
+	e.m.Unlock()
+
+	for _, h := range handlers {
+		if h.matcher(call) {
+			if r := h.handler(call); r != nil {
+				return r, nil
+			}
+			return &FakeResult{}, nil
+		}
+	}
+
+	return nil, errorz.Errorf("unexpected command: %v", strings.Join(call.GetArgs(), " "))
+}
+
+func (r *FakeResult) getError(captureStderr bool) error {
+	switch {
+	case r.Err != nil:
+		return r.Err
+	case r.ExitCode != 0 && captureStderr:
+		return shellz.NewSimulatedExitError("", r.ExitCode, []byte(r.Stderr))
+	case r.ExitCode != 0:
+		return shellz.NewSimulatedExitError("", r.ExitCode, nil)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package tshellz_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
+)
+
+func TestFakeExecutor(t *testing.T) {
+	g := NewWithT(t)
+
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	e := tshellz.NewFakeExecutor().
+		Handle(tshellz.MatchArgs(regexp.MustCompile(`^go vet`)), tshellz.Returns("", "vet: bad\n", 2)).
+		Handle(tshellz.MatchCommand("go"), tshellz.Returns("ok\n", "warn\n", 0)).
+		Handle(tshellz.MatchCommand("cat"), func(call *tshellz.FakeCall) *tshellz.FakeResult {
+			return &tshellz.FakeResult{Stdout: strings.ToUpper(call.Stdin)}
+		}).
+		Handle(tshellz.MatchCommand("missing"), func(*tshellz.FakeCall) *tshellz.FakeResult {
+			return &tshellz.FakeResult{Err: errors.New("not found")}
+		}).
+		Handle(tshellz.MatchCommand("nil"), func(*tshellz.FakeCall) *tshellz.FakeResult {
+			return nil
+		})
+
+	newCommand := func(cmd string, params ...string) *shellz.Command {
+		return shellz.NewCommand(cmd, params...).SetExecutor(e).SetEcho(false)
+	}
+
+	g.Expect(newCommand("go", "build").Run()).To(Succeed())
+
+	err := newCommand("go", "vet", "./...").Run()
+	g.Expect(err).To(MatchError("execution error: go vet ./...: exit status 2"))
+	eErr, ok := errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(2))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("vet: bad\n"))
+
+	_, err = newCommand("go", "vet").Output(false)
+	eErr, ok = errorz.As[*shellz.ExecutionError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.GetExitCode()).To(Equal(2))
+	g.Expect(eErr.GetCapturedStderr()).To(Equal("vet: bad\n"))
+
+	g.Expect(newCommand("go", "env").MustOutputString(false)).To(Equal("ok\n"))
+	g.Expect(newCommand("go", "env").MustCombinedOutputString()).To(Equal("ok\nwarn\n"))
+	g.Expect(newCommand("cat").SetIn(strings.NewReader("in")).MustOutputString(false)).To(Equal("IN"))
+	g.Expect(newCommand("cat").Pipe(newCommand("cat")).MustOutputString(false)).To(Equal(""))
+	g.Expect(newCommand("nil").Run()).To(Succeed())
+	g.Expect(newCommand("missing").Run()).To(MatchError("execution error: missing: not found"))
+	g.Expect(newCommand("unknown", "p").Run()).To(MatchError("execution error: unknown p: unexpected command: unknown p"))
+	g.Expect(newCommand("go").Exec()).To(Succeed())
+	g.Expect(newCommand("go", "vet").Exec()).To(MatchError("execution error: go vet: exit status 2"))
+
+	lines := make([]string, 0)
+	g.Expect(newCommand("go", "list").Lines(func(line string) { lines = append(lines, line) })).To(Succeed())
+	g.Expect(lines).To(ConsistOf("ok", "warn"))
+
+	p := newCommand("go", "run").MustStart()
+	p.MustWaitForPort(context.Background(), "localhost:8080")
+	g.Expect(p.Kill()).To(Succeed())
+	g.Expect(p.Wait()).To(Succeed())
+	g.Expect(p.GetLines()).To(ConsistOf("ok", "warn"))
+
+	_, err = newCommand("missing").Start()
+	g.Expect(err).To(MatchError("execution error: missing: not found"))
+
+	history := e.GetHistory()
+	g.Expect(history).To(HaveLen(16))
+	g.Expect(history[0].Mode).To(Equal(tshellz.ModeRun))
+	g.Expect(history[0].GetArgs()).To(Equal([]string{"go", "build"}))
+	g.Expect(history[0].Command.GetParams()).To(Equal([]string{"build"}))
+	g.Expect(history[5].Stdin).To(Equal("in"))
+	g.Expect(history[12].Mode).To(Equal(tshellz.ModeExec))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	g.Expect(outBuf).To(Equal("ok\n"))
+	g.Expect(errBuf).To(Equal("warn\nvet: bad\n"))
+}
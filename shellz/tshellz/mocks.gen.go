@@ -10,8 +10,11 @@
 package tshellz
 
 import (
+	context "context"
+	net "net"
 	exec "os/exec"
 	reflect "reflect"
+	syscall "syscall"
 
 	shellz "github.com/ibrt/golang-lib/shellz"
 	gomock "go.uber.org/mock/gomock"
@@ -157,6 +160,44 @@ func (c_2 *MockExecutorExecCmdRunCall) DoAndReturn(f func(*shellz.Command, *exec
 	return c_2
 }
 
+// ExecCmdSignal mocks base method.
+func (m *MockExecutor) ExecCmdSignal(c *shellz.Command, cmd *exec.Cmd, sig syscall.Signal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecCmdSignal", c, cmd, sig)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecCmdSignal indicates an expected call of ExecCmdSignal.
+func (mr *MockExecutorMockRecorder) ExecCmdSignal(c, cmd, sig any) *MockExecutorExecCmdSignalCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecCmdSignal", reflect.TypeOf((*MockExecutor)(nil).ExecCmdSignal), c, cmd, sig)
+	return &MockExecutorExecCmdSignalCall{Call: call}
+}
+
+// MockExecutorExecCmdSignalCall wrap *gomock.Call
+type MockExecutorExecCmdSignalCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c_2 *MockExecutorExecCmdSignalCall) Return(arg0 error) *MockExecutorExecCmdSignalCall {
+	c_2.Call = c_2.Call.Return(arg0)
+	return c_2
+}
+
+// Do rewrite *gomock.Call.Do
+func (c_2 *MockExecutorExecCmdSignalCall) Do(f func(*shellz.Command, *exec.Cmd, syscall.Signal) error) *MockExecutorExecCmdSignalCall {
+	c_2.Call = c_2.Call.Do(f)
+	return c_2
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c_2 *MockExecutorExecCmdSignalCall) DoAndReturn(f func(*shellz.Command, *exec.Cmd, syscall.Signal) error) *MockExecutorExecCmdSignalCall {
+	c_2.Call = c_2.Call.DoAndReturn(f)
+	return c_2
+}
+
 // ExecCmdStart mocks base method.
 func (m *MockExecutor) ExecCmdStart(c *shellz.Command, cmd *exec.Cmd) error {
 	m.ctrl.T.Helper()
@@ -272,6 +313,45 @@ func (c_2 *MockExecutorExecLookPathCall) DoAndReturn(f func(*shellz.Command, str
 	return c_2
 }
 
+// NetDialContext mocks base method.
+func (m *MockExecutor) NetDialContext(ctx context.Context, c *shellz.Command, network, address string) (net.Conn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NetDialContext", ctx, c, network, address)
+	ret0, _ := ret[0].(net.Conn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NetDialContext indicates an expected call of NetDialContext.
+func (mr *MockExecutorMockRecorder) NetDialContext(ctx, c, network, address any) *MockExecutorNetDialContextCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NetDialContext", reflect.TypeOf((*MockExecutor)(nil).NetDialContext), ctx, c, network, address)
+	return &MockExecutorNetDialContextCall{Call: call}
+}
+
+// MockExecutorNetDialContextCall wrap *gomock.Call
+type MockExecutorNetDialContextCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c_2 *MockExecutorNetDialContextCall) Return(arg0 net.Conn, arg1 error) *MockExecutorNetDialContextCall {
+	c_2.Call = c_2.Call.Return(arg0, arg1)
+	return c_2
+}
+
+// Do rewrite *gomock.Call.Do
+func (c_2 *MockExecutorNetDialContextCall) Do(f func(context.Context, *shellz.Command, string, string) (net.Conn, error)) *MockExecutorNetDialContextCall {
+	c_2.Call = c_2.Call.Do(f)
+	return c_2
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c_2 *MockExecutorNetDialContextCall) DoAndReturn(f func(context.Context, *shellz.Command, string, string) (net.Conn, error)) *MockExecutorNetDialContextCall {
+	c_2.Call = c_2.Call.DoAndReturn(f)
+	return c_2
+}
+
 // OSChdir mocks base method.
 func (m *MockExecutor) OSChdir(c *shellz.Command, dir string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,532 @@
+package tshellz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/filez"
+	"github.com/ibrt/golang-lib/jsonz"
+	"github.com/ibrt/golang-lib/shellz"
+)
+
+var (
+	_ shellz.Executor = (*RecordingExecutor)(nil)
+	_ shellz.Executor = (*ReplayingExecutor)(nil)
+)
+
+// Recorded command modes, named after the corresponding Executor methods.
+const (
+	ModeCombinedOutput = "ExecCmdCombinedOutput"
+	ModeOutput         = "ExecCmdOutput"
+	ModeRun            = "ExecCmdRun"
+	ModeStart          = "ExecCmdStart"
+)
+
+// RecordedCommand describes a command recorded by a RecordingExecutor.
+// In ModeCombinedOutput, the combined standard output and error are recorded as Stdout.
+type RecordedCommand struct {
+	Mode      string            `json:"mode"`
+	Cmd       string            `json:"cmd"`
+	Params    []string          `json:"params,omitempty"`
+	Dir       string            `json:"dir,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Stdin     string            `json:"stdin,omitempty"`
+	Stdout    string            `json:"stdout,omitempty"`
+	Stderr    string            `json:"stderr,omitempty"`
+	ExitCode  int               `json:"exitCode"`
+	ExitError bool              `json:"exitError,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func newRecordedCommand(mode string, c *shellz.Command, cmd *exec.Cmd) *RecordedCommand {
+	return &RecordedCommand{
+		Mode:   mode,
+		Cmd:    cmd.Args[0],
+		Params: c.GetParams(),
+		Dir:    c.GetDir(),
+		Env:    c.GetEnv(),
+	}
+}
+
+func (rc *RecordedCommand) setError(err error) {
+	if err == nil {
+		return
+	}
+
+	rc.ExitCode = -1
+	rc.Error = err.Error()
+
+	if eErr, ok := errorz.As[*exec.ExitError](err); ok {
+		rc.ExitCode = eErr.ExitCode()
+		rc.ExitError = true
+
+		if rc.Stderr == "" {
+			rc.Stderr = string(eErr.Stderr)
+		}
+	}
+}
+
+func (rc *RecordedCommand) getError(captureStderr bool) error {
+	switch {
+	case rc.Error == "":
+		return nil
+	case rc.ExitError && captureStderr:
+		return shellz.NewSimulatedExitError(rc.Error, rc.ExitCode, []byte(rc.Stderr))
+	case rc.ExitError:
+		return shellz.NewSimulatedExitError(rc.Error, rc.ExitCode, nil)
+	default:
+		return errors.New(rc.Error)
+	}
+}
+
+func (rc *RecordedCommand) matches(other *RecordedCommand) bool {
+	return rc.Mode == other.Mode &&
+		rc.Cmd == other.Cmd &&
+		slices.Equal(rc.Params, other.Params) &&
+		rc.Dir == other.Dir &&
+		maps.Equal(rc.Env, other.Env)
+}
+
+type pendingRecording struct {
+	rc         *RecordedCommand
+	stdin      *bytes.Buffer
+	stdout     *bytes.Buffer
+	stderr     *bytes.Buffer
+	afterStart []func()
+	wait       []func()
+}
+
+// RecordingExecutor is an Executor that wraps another Executor (usually *shellz.RealExecutor) and records the
+// commands it runs, which can then be saved to a fixture file and served back by a ReplayingExecutor.
+// Standard input is recorded unless it is an *os.File.
+type RecordingExecutor struct {
+	wrapped  shellz.Executor
+	m        *sync.Mutex
+	recorded []*RecordedCommand
+	pending  map[*exec.Cmd]*pendingRecording
+}
+
+// NewRecordingExecutor initializes a new RecordingExecutor.
+func NewRecordingExecutor(wrapped shellz.Executor) *RecordingExecutor {
+	return &RecordingExecutor{
+		wrapped:  wrapped,
+		m:        &sync.Mutex{},
+		recorded: make([]*RecordedCommand, 0),
+		pending:  make(map[*exec.Cmd]*pendingRecording),
+	}
+}
+
+// GetRecorded returns the commands recorded so far, in order of completion.
+func (e *RecordingExecutor) GetRecorded() []*RecordedCommand {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return slices.Clone(e.recorded)
+}
+
+// MustSave saves the commands recorded so far to the given fixture file.
+func (e *RecordingExecutor) MustSave(filePath string) {
+	filez.MustWriteFile(filePath, 0777, 0666, jsonz.MustMarshalPretty(e.GetRecorded()))
+}
+
+// ExecCmdCombinedOutput implements the shellz.Executor interface.
+func (e *RecordingExecutor) ExecCmdCombinedOutput(c *shellz.Command, cmd *exec.Cmd) ([]byte, error) {
+	p := e.begin(ModeCombinedOutput, c, cmd)
+	out, err := e.wrapped.ExecCmdCombinedOutput(c, cmd)
+	_, _ = p.stdout.Write(out)
+	e.end(p, err)
+	return out, err
+}
+
+// ExecCmdOutput implements the shellz.Executor interface.
+func (e *RecordingExecutor) ExecCmdOutput(c *shellz.Command, cmd *exec.Cmd) ([]byte, error) {
+	p := e.begin(ModeOutput, c, cmd)
+
+	if cmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, p.stderr)
+	}
+
+	out, err := e.wrapped.ExecCmdOutput(c, cmd)
+	_, _ = p.stdout.Write(out)
+	e.end(p, err)
+	return out, err
+}
+
+// ExecCmdRun implements the shellz.Executor interface.
+func (e *RecordingExecutor) ExecCmdRun(c *shellz.Command, cmd *exec.Cmd) error {
+	p := e.begin(ModeRun, c, cmd)
+	cmd.Stdout = teeWriter(cmd.Stdout, p.stdout)
+	cmd.Stderr = teeWriter(cmd.Stderr, p.stderr)
+	err := e.wrapped.ExecCmdRun(c, cmd)
+	e.end(p, err)
+	return err
+}
+
+// ExecCmdSignal implements the shellz.Executor interface.
+func (e *RecordingExecutor) ExecCmdSignal(c *shellz.Command, cmd *exec.Cmd, sig syscall.Signal) error {
+	return e.wrapped.ExecCmdSignal(c, cmd, sig)
+}
+
+// ExecCmdStart implements the shellz.Executor interface.
+// The command is recorded when ExecCmdWait returns, or immediately if it fails to start.
+func (e *RecordingExecutor) ExecCmdStart(c *shellz.Command, cmd *exec.Cmd) error {
+	p := e.begin(ModeStart, c, cmd)
+	cmd.Stdout = p.teeStartWriter(cmd.Stdout, p.stdout)
+	cmd.Stderr = p.teeStartWriter(cmd.Stderr, p.stderr)
+
+	err := e.wrapped.ExecCmdStart(c, cmd)
+
+	for _, f := range p.afterStart {
+		f()
+	}
+
+	if err != nil {
+		e.end(p, err)
+		return err
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.pending[cmd] = p
+
+	return nil
+}
+
+// ExecCmdWait implements the shellz.Executor interface.
+func (e *RecordingExecutor) ExecCmdWait(c *shellz.Command, cmd *exec.Cmd) error {
+	err := e.wrapped.ExecCmdWait(c, cmd)
+
+	e.m.Lock()
+	p, ok := e.pending[cmd]
+	delete(e.pending, cmd)
+	e.m.Unlock()
+
+	if ok {
+		e.end(p, err)
+	}
+
+	return err
+}
+
+// ExecLookPath implements the shellz.Executor interface.
+func (e *RecordingExecutor) ExecLookPath(c *shellz.Command, file string) (string, error) {
+	return e.wrapped.ExecLookPath(c, file)
+}
+
+// NetDialContext implements the shellz.Executor interface.
+func (e *RecordingExecutor) NetDialContext(ctx context.Context, c *shellz.Command, network, address string) (net.Conn, error) {
+	return e.wrapped.NetDialContext(ctx, c, network, address)
+}
+
+// OSChdir implements the shellz.Executor interface.
+func (e *RecordingExecutor) OSChdir(c *shellz.Command, dir string) error {
+	return e.wrapped.OSChdir(c, dir)
+}
+
+// SyscallExec implements the shellz.Executor interface.
+func (e *RecordingExecutor) SyscallExec(c *shellz.Command, argv0 string, argv []string, envv []string) error {
+	return e.wrapped.SyscallExec(c, argv0, argv, envv)
+}
+
+func (e *RecordingExecutor) begin(mode string, c *shellz.Command, cmd *exec.Cmd) *pendingRecording {
+	p := &pendingRecording{
+		rc:     newRecordedCommand(mode, c, cmd),
+		stdout: &bytes.Buffer{},
+		stderr: &bytes.Buffer{},
+	}
+
+	if _, ok := cmd.Stdin.(*os.File); cmd.Stdin != nil && !ok {
+		p.stdin = &bytes.Buffer{}
+		cmd.Stdin = io.TeeReader(cmd.Stdin, p.stdin)
+	}
+
+	return p
+}
+
+func (e *RecordingExecutor) end(p *pendingRecording, err error) {
+	for _, f := range p.wait {
+		f()
+	}
+
+	if p.stdin != nil {
+		p.rc.Stdin = p.stdin.String()
+	}
+
+	p.rc.Stdout = p.stdout.String()
+	p.rc.Stderr = p.stderr.String()
+	p.rc.setError(err)
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.recorded = append(e.recorded, p.rc)
+}
+
+// teeStartWriter is like teeWriter, but it handles *os.File writers (e.g. pipes created by "exec.Cmd.StdoutPipe")
+// by interposing another pipe, so that the original file can be closed by its owner as soon as the command starts.
+func (p *pendingRecording) teeStartWriter(w io.Writer, buf *bytes.Buffer) io.Writer {
+	f, ok := w.(*os.File)
+	if !ok {
+		return teeWriter(w, buf)
+	}
+
+	df := shellz.DupFile(f)
+
+	r, pw, err := os.Pipe()
+	errorz.MaybeMustWrap(err)
+
+	doneC := make(chan struct{})
+
+	go func() {
+		defer close(doneC)
+		_, _ = io.Copy(io.MultiWriter(buf, df), r)
+		_ = df.Close()
+		_ = r.Close()
+	}()
+
+	p.afterStart = append(p.afterStart, func() { _ = pw.Close() })
+	p.wait = append(p.wait, func() { <-doneC })
+	return pw
+}
+
+func teeWriter(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+
+	return io.MultiWriter(w, buf)
+}
+
+// ReplayingExecutor is an Executor that serves back the commands recorded by a RecordingExecutor, without running
+// anything. Each recorded command is served at most once, to the first matching request (by mode, command, params,
+// dir, and env). Unexpected commands fail with an error. Standard input is read and compared with the recorded one,
+// unless it is an *os.File or the command is started in the background.
+//
+// When a command is started in the background, its recorded output is written asynchronously. Output writers which
+// are *os.File, other than os.Stdout and os.Stderr, are assumed to be pipes owned by the command (e.g. created by
+// "exec.Cmd.StdoutPipe"), and are closed once the output has been written, as it happens when a real command exits.
+type ReplayingExecutor struct {
+	m         *sync.Mutex
+	recorded  []*RecordedCommand
+	remaining []bool
+	started   map[*exec.Cmd]*replayedStart
+}
+
+type replayedStart struct {
+	rc   *RecordedCommand
+	wait []func()
+}
+
+// NewReplayingExecutor initializes a new ReplayingExecutor.
+func NewReplayingExecutor(recorded []*RecordedCommand) *ReplayingExecutor {
+	remaining := make([]bool, len(recorded))
+
+	for i := range remaining {
+		remaining[i] = true
+	}
+
+	return &ReplayingExecutor{
+		m:         &sync.Mutex{},
+		recorded:  slices.Clone(recorded),
+		remaining: remaining,
+		started:   make(map[*exec.Cmd]*replayedStart),
+	}
+}
+
+// MustLoadReplayingExecutor initializes a new ReplayingExecutor from a fixture file saved by a RecordingExecutor.
+func MustLoadReplayingExecutor(filePath string) *ReplayingExecutor {
+	return NewReplayingExecutor(jsonz.MustUnmarshal[[]*RecordedCommand](filez.MustReadFile(filePath)))
+}
+
+// GetRemaining returns the recorded commands that have not been served yet.
+func (e *ReplayingExecutor) GetRemaining() []*RecordedCommand {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	remaining := make([]*RecordedCommand, 0)
+
+	for i, rc := range e.recorded {
+		if e.remaining[i] {
+			remaining = append(remaining, rc)
+		}
+	}
+
+	return remaining
+}
+
+// ExecCmdCombinedOutput implements the shellz.Executor interface.
+func (e *ReplayingExecutor) ExecCmdCombinedOutput(c *shellz.Command, cmd *exec.Cmd) ([]byte, error) {
+	rc, err := e.serve(ModeCombinedOutput, c, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(rc.Stdout), rc.getError(false)
+}
+
+// ExecCmdOutput implements the shellz.Executor interface.
+func (e *ReplayingExecutor) ExecCmdOutput(c *shellz.Command, cmd *exec.Cmd) ([]byte, error) {
+	rc, err := e.serve(ModeOutput, c, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, rc.Stderr)
+	}
+
+	return []byte(rc.Stdout), rc.getError(cmd.Stderr == nil)
+}
+
+// ExecCmdRun implements the shellz.Executor interface.
+func (e *ReplayingExecutor) ExecCmdRun(c *shellz.Command, cmd *exec.Cmd) error {
+	rc, err := e.serve(ModeRun, c, cmd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Stdout != nil {
+		_, _ = io.WriteString(cmd.Stdout, rc.Stdout)
+	}
+
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, rc.Stderr)
+	}
+
+	return rc.getError(false)
+}
+
+// ExecCmdSignal implements the shellz.Executor interface. It does nothing.
+func (e *ReplayingExecutor) ExecCmdSignal(_ *shellz.Command, _ *exec.Cmd, _ syscall.Signal) error {
+	return nil
+}
+
+// ExecCmdStart implements the shellz.Executor interface.
+func (e *ReplayingExecutor) ExecCmdStart(c *shellz.Command, cmd *exec.Cmd) error {
+	rc, err := e.serve(ModeStart, c, cmd)
+	if err != nil {
+		return err
+	}
+
+	if rc.Error != "" && !rc.ExitError {
+		return rc.getError(false)
+	}
+
+	s := &replayedStart{
+		rc: rc,
+		wait: []func(){
+			writeStartOutput(cmd.Stdout, rc.Stdout),
+			writeStartOutput(cmd.Stderr, rc.Stderr),
+		},
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.started[cmd] = s
+
+	return nil
+}
+
+// ExecCmdWait implements the shellz.Executor interface.
+func (e *ReplayingExecutor) ExecCmdWait(_ *shellz.Command, cmd *exec.Cmd) error {
+	e.m.Lock()
+	s, ok := e.started[cmd]
+	delete(e.started, cmd)
+	e.m.Unlock()
+
+	if !ok {
+		return errorz.Errorf("command not started: %v", cmd.Args[0])
+	}
+
+	for _, f := range s.wait {
+		f()
+	}
+
+	return s.rc.getError(false)
+}
+
+// ExecLookPath implements the shellz.Executor interface. It returns the given file.
+func (e *ReplayingExecutor) ExecLookPath(_ *shellz.Command, file string) (string, error) {
+	return file, nil
+}
+
+// NetDialContext implements the shellz.Executor interface. It always succeeds, returning one end of a "net.Pipe".
+func (e *ReplayingExecutor) NetDialContext(_ context.Context, _ *shellz.Command, _, _ string) (net.Conn, error) {
+	c1, c2 := net.Pipe()
+	_ = c2.Close()
+	return c1, nil
+}
+
+// OSChdir implements the shellz.Executor interface. It does nothing.
+func (e *ReplayingExecutor) OSChdir(_ *shellz.Command, _ string) error {
+	return nil
+}
+
+// SyscallExec implements the shellz.Executor interface. It always fails.
+func (e *ReplayingExecutor) SyscallExec(_ *shellz.Command, argv0 string, _ []string, _ []string) error {
+	return errorz.Errorf("exec is not supported by ReplayingExecutor: %v", argv0)
+}
+
+func (e *ReplayingExecutor) serve(mode string, c *shellz.Command, cmd *exec.Cmd) (*RecordedCommand, error) {
+	req := newRecordedCommand(mode, c, cmd)
+
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	for i, rc := range e.recorded {
+		if !e.remaining[i] || !rc.matches(req) {
+			continue
+		}
+
+		e.remaining[i] = false
+
+		if _, ok := cmd.Stdin.(*os.File); cmd.Stdin != nil && !ok && mode != ModeStart {
+			buf, err := io.ReadAll(cmd.Stdin)
+			if err != nil {
+				return nil, errorz.Wrap(err)
+			}
+
+			if string(buf) != rc.Stdin {
+				return nil, errorz.Errorf("unexpected stdin for command: %v: %q", cmd.Args[0], string(buf))
+			}
+		}
+
+		return rc, nil
+	}
+
+	return nil, errorz.Errorf("unexpected command: %v", jsonz.MustMarshalString(req))
+}
+
+func writeStartOutput(w io.Writer, data string) func() {
+	if w == nil {
+		return func() {}
+	}
+
+	doneC := make(chan struct{})
+
+	if f, ok := w.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		df := shellz.DupFile(f)
+		_ = f.Close()
+
+		go func() {
+			defer close(doneC)
+			_, _ = io.WriteString(df, data)
+			_ = df.Close()
+		}()
+	} else {
+		go func() {
+			defer close(doneC)
+			_, _ = io.WriteString(w, data)
+		}()
+	}
+
+	return func() { <-doneC }
+}
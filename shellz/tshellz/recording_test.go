@@ -0,0 +1,126 @@
+package tshellz_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/filez"
+	"github.com/ibrt/golang-lib/fixturez"
+	"github.com/ibrt/golang-lib/shellz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
+)
+
+func runScenario(g *WithT, executor shellz.Executor) []string {
+	fixturez.MustBeginOutputCapture(fixturez.OutputSetupStandard, fixturez.GetOutputSetupColor(false), fixturez.OutputSetupTable)
+	defer fixturez.ResetOutputCapture()
+
+	results := make([]string, 0)
+
+	addResult := func(out any, err error) {
+		if eErr, ok := errorz.As[*shellz.ExecutionError](err); ok {
+			results = append(results, fmt.Sprintf("%q %v %v %q", out, err, eErr.GetExitCode(), eErr.GetCapturedStderr()))
+		} else {
+			results = append(results, fmt.Sprintf("%q %v", out, err))
+		}
+	}
+
+	newCommand := func(cmd string, params ...string) *shellz.Command {
+		return shellz.NewCommand(cmd, params...).SetExecutor(executor).SetEcho(false)
+	}
+
+	addResult(nil, newCommand("sh", "-c", "cat; echo err >&2").
+		SetIn(strings.NewReader("in")).
+		SetEnv("K", "V").
+		SetDir(filez.MustGetwd()).
+		Run())
+
+	addResult(newCommand("sh", "-c", "echo out; echo err >&2; exit 3").OutputString(false))
+	addResult(newCommand("sh", "-c", "echo out; echo err >&2").CombinedOutputString())
+	addResult(newCommand("cae0e988-f55b-4803-a471-a877b686d1a8").OutputString(true))
+
+	lines := make([]string, 0)
+	addResult(nil, newCommand("sh", "-c", "echo 1; echo 2 >&2; exit 4").Lines(func(line string) {
+		lines = append(lines, line)
+	}))
+	slices.Sort(lines)
+	addResult(lines, nil)
+
+	p := newCommand("sh", "-c", "echo ready; exit 2").MustStart()
+	addResult(nil, p.Wait())
+	addResult(p.GetLines(), nil)
+
+	addResult(newCommand("printf", `b\na\n`).Pipe(newCommand("sort")).OutputString(false))
+
+	outBuf, errBuf := fixturez.MustEndOutputCapture()
+	return append(results, outBuf, errBuf)
+}
+
+func TestRecordReplay(t *testing.T) {
+	g := NewWithT(t)
+
+	dirPath := filez.MustCreateTempDir()
+	defer filez.MustRemoveAll(dirPath)
+	filePath := filepath.Join(dirPath, "fixture.json")
+
+	rec := tshellz.NewRecordingExecutor(&shellz.RealExecutor{})
+	recorded := runScenario(g, rec)
+	rec.MustSave(filePath)
+
+	g.Expect(recorded).To(Equal([]string{
+		`%!q(<nil>) <nil>`,
+		`"" execution error: sh -c 'echo out; echo err >&2; exit 3': exit status 3 3 "err\n"`,
+		`"out\nerr\n" <nil>`,
+		`"" execution error: cae0e988-f55b-4803-a471-a877b686d1a8: exec: "cae0e988-f55b-4803-a471-a877b686d1a8": executable file not found in $PATH -1 ""`,
+		`%!q(<nil>) execution error: sh -c 'echo 1; echo 2 >&2; exit 4': exit status 4 4 "2\n"`,
+		`["1" "2"] <nil>`,
+		`%!q(<nil>) execution error: sh -c 'echo ready; exit 2': exit status 2 2 ""`,
+		`["ready"] <nil>`,
+		`"a\nb\n" <nil>`,
+		"in",
+		"err\n",
+	}))
+
+	g.Expect(rec.GetRecorded()).To(HaveLen(8))
+	g.Expect(rec.GetRecorded()[0]).To(Equal(&tshellz.RecordedCommand{
+		Mode:   tshellz.ModeRun,
+		Cmd:    "sh",
+		Params: []string{"-c", "cat; echo err >&2"},
+		Dir:    filez.MustGetwd(),
+		Env:    map[string]string{"K": "V"},
+		Stdin:  "in",
+		Stdout: "in",
+		Stderr: "err\n",
+	}))
+
+	rep := tshellz.MustLoadReplayingExecutor(filePath)
+	g.Expect(runScenario(g, rep)).To(Equal(recorded))
+	g.Expect(rep.GetRemaining()).To(BeEmpty())
+}
+
+func TestReplay_Errors(t *testing.T) {
+	g := NewWithT(t)
+
+	rep := tshellz.NewReplayingExecutor([]*tshellz.RecordedCommand{
+		{
+			Mode:   tshellz.ModeOutput,
+			Cmd:    "cat",
+			Stdin:  "in",
+			Stdout: "in",
+		},
+	})
+
+	_, err := shellz.NewCommand("cat").SetExecutor(rep).SetIn(strings.NewReader("other")).Output(false)
+	g.Expect(err).To(MatchError(`execution error: cat: unexpected stdin for command: cat: "other"`))
+
+	_, err = shellz.NewCommand("cat").SetExecutor(rep).SetIn(strings.NewReader("in")).Output(false)
+	g.Expect(err).To(MatchError(`execution error: cat: unexpected command: {"mode":"ExecCmdOutput","cmd":"cat","exitCode":0}`))
+
+	g.Expect(shellz.NewCommand("ls").SetExecutor(rep).SetEcho(false).Exec()).
+		To(MatchError("execution error: ls: exec is not supported by ReplayingExecutor: ls"))
+}
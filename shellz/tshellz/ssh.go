@@ -0,0 +1,338 @@
+package tshellz
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os/exec"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ibrt/golang-lib/errorz"
+)
+
+var (
+	sshSignals = map[ssh.Signal]syscall.Signal{
+		ssh.SIGABRT: syscall.SIGABRT,
+		ssh.SIGALRM: syscall.SIGALRM,
+		ssh.SIGFPE:  syscall.SIGFPE,
+		ssh.SIGHUP:  syscall.SIGHUP,
+		ssh.SIGILL:  syscall.SIGILL,
+		ssh.SIGINT:  syscall.SIGINT,
+		ssh.SIGKILL: syscall.SIGKILL,
+		ssh.SIGPIPE: syscall.SIGPIPE,
+		ssh.SIGQUIT: syscall.SIGQUIT,
+		ssh.SIGSEGV: syscall.SIGSEGV,
+		ssh.SIGTERM: syscall.SIGTERM,
+		ssh.SIGUSR1: syscall.SIGUSR1,
+		ssh.SIGUSR2: syscall.SIGUSR2,
+	}
+)
+
+// SSHServer is an in-process SSH server which stands in for a remote host when testing *shellz.SSHExecutor. It runs
+// the commands it receives on the host using "sh -c", forwards signals to their process group, and reports their exit
+// status. It also supports "direct-tcpip" channels (see ssh.Client.Dial). Clients are not authenticated.
+type SSHServer struct {
+	listener  net.Listener
+	config    *ssh.ServerConfig
+	hostKey   ssh.PublicKey
+	m         *sync.Mutex
+	conns     map[net.Conn]struct{}
+	history   []string
+	isClosed  bool
+	waitGroup *sync.WaitGroup
+}
+
+// NewSSHServer initializes a new SSHServer listening on a random local port.
+func NewSSHServer() (*SSHServer, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errorz.Wrap(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, errorz.Wrap(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errorz.Wrap(err)
+	}
+
+	config := &ssh.ServerConfig{
+		NoClientAuth: true,
+	}
+	config.AddHostKey(signer)
+
+	s := &SSHServer{
+		listener:  listener,
+		config:    config,
+		hostKey:   signer.PublicKey(),
+		m:         &sync.Mutex{},
+		conns:     make(map[net.Conn]struct{}),
+		history:   make([]string, 0),
+		waitGroup: &sync.WaitGroup{},
+	}
+
+	s.waitGroup.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// MustNewSSHServer is like NewSSHServer but panics on error.
+func MustNewSSHServer() *SSHServer {
+	s, err := NewSSHServer()
+	errorz.MaybeMustWrap(err)
+	return s
+}
+
+// GetAddress returns the address the server is listening on.
+func (s *SSHServer) GetAddress() string {
+	return s.listener.Addr().String()
+}
+
+// GetClientConfig returns a client configuration which can be used to connect to the server.
+func (s *SSHServer) GetClientConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User:            "shellz",
+		HostKeyCallback: ssh.FixedHostKey(s.hostKey),
+	}
+}
+
+// GetHistory returns the command lines received so far.
+func (s *SSHServer) GetHistory() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return slices.Clone(s.history)
+}
+
+// Close stops the server and closes all the connections.
+func (s *SSHServer) Close() error {
+	s.m.Lock()
+	s.isClosed = true
+	err := s.listener.Close()
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.m.Unlock()
+
+	s.waitGroup.Wait()
+	return errorz.MaybeWrap(err)
+}
+
+func (s *SSHServer) serve() {
+	defer s.waitGroup.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.m.Lock()
+		if s.isClosed {
+			s.m.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.waitGroup.Add(1)
+		s.m.Unlock()
+
+		go s.handleConn(conn)
+	}
+}
+
+func (s *SSHServer) handleConn(conn net.Conn) {
+	defer s.waitGroup.Done()
+
+	defer func() {
+		s.m.Lock()
+		defer s.m.Unlock()
+		delete(s.conns, conn)
+		_ = conn.Close()
+	}()
+
+	sConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer func() { _ = sConn.Close() }()
+
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		switch nc.ChannelType() {
+		case "session":
+			go s.handleSession(nc)
+		case "direct-tcpip":
+			go s.handleDirectTCPIP(nc)
+		default:
+			_ = nc.Reject(ssh.UnknownChannelType, "unknown channel type")
+		}
+	}
+}
+
+func (s *SSHServer) handleSession(nc ssh.NewChannel) {
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = ch.Close() }()
+
+	var cmd *exec.Cmd
+	var doneC <-chan struct{}
+
+	for req := range reqs {
+		switch req.Type {
+		case "exec":
+			msg := &struct{ Command string }{}
+			if cmd != nil || ssh.Unmarshal(req.Payload, msg) != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+
+			s.m.Lock()
+			s.history = append(s.history, msg.Command)
+			s.m.Unlock()
+
+			if cmd, doneC = startSSHCommand(ch, msg.Command); cmd == nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+
+			_ = req.Reply(true, nil)
+		case "signal":
+			msg := &struct{ Signal string }{}
+			if cmd != nil && ssh.Unmarshal(req.Payload, msg) == nil {
+				if sig, ok := sshSignals[ssh.Signal(msg.Signal)]; ok {
+					killSSHCommand(cmd, doneC, sig)
+				}
+			}
+		default:
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}
+
+	if cmd != nil {
+		killSSHCommand(cmd, doneC, syscall.SIGKILL)
+	}
+}
+
+// startSSHCommand starts the command, and reports its exit status on the channel once it exits. It returns a channel
+// which is closed once the command has been waited for, or nil if the command cannot be started.
+func startSSHCommand(ch ssh.Channel, command string) (*exec.Cmd, <-chan struct{}) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = ch
+	cmd.Stderr = ch.Stderr()
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil
+	}
+
+	doneC := make(chan struct{})
+
+	go func() {
+		_, _ = io.Copy(stdin, ch)
+		_ = stdin.Close()
+	}()
+
+	go func() {
+		err := cmd.Wait()
+		close(doneC)
+		eErr := &exec.ExitError{}
+
+		switch {
+		case err == nil:
+			_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(&struct{ Status uint32 }{0}))
+		case errors.As(err, &eErr) && eErr.ExitCode() >= 0:
+			_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(&struct{ Status uint32 }{uint32(eErr.ExitCode())}))
+		case errors.As(err, &eErr):
+			_, _ = ch.SendRequest("exit-signal", false, ssh.Marshal(&struct {
+				Signal     string
+				CoreDumped bool
+				Error      string
+				Lang       string
+			}{
+				Signal: string(getSSHSignal(eErr.Sys().(syscall.WaitStatus).Signal())),
+			}))
+		}
+
+		_ = ch.Close()
+	}()
+
+	return cmd, doneC
+}
+
+// killSSHCommand sends a signal to the process group of the command, unless it has already been waited for.
+func killSSHCommand(cmd *exec.Cmd, doneC <-chan struct{}, sig syscall.Signal) {
+	select {
+	case <-doneC:
+	default:
+		_ = syscall.Kill(-cmd.Process.Pid, sig)
+	}
+}
+
+func getSSHSignal(sig syscall.Signal) ssh.Signal {
+	for name, s := range sshSignals {
+		if s == sig {
+			return name
+		}
+	}
+
+	return ssh.Signal(strconv.Itoa(int(sig)))
+}
+
+func (s *SSHServer) handleDirectTCPIP(nc ssh.NewChannel) {
+	msg := &struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}{}
+
+	if err := ssh.Unmarshal(nc.ExtraData(), msg); err != nil {
+		_ = nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(msg.Host, strconv.Itoa(int(msg.Port))))
+	if err != nil {
+		_ = nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = ch.Close() }()
+
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(conn, ch)
+		_ = conn.(*net.TCPConn).CloseWrite()
+	}()
+
+	_, _ = io.Copy(ch, conn)
+	_ = ch.CloseWrite()
+}
@@ -0,0 +1,64 @@
+package tshellz_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ibrt/golang-lib/errorz"
+	"github.com/ibrt/golang-lib/shellz/tshellz"
+)
+
+func TestSSHServer(t *testing.T) {
+	g := NewWithT(t)
+
+	s := tshellz.MustNewSSHServer()
+	g.Expect(s.GetAddress()).To(HavePrefix("127.0.0.1:"))
+
+	client, err := ssh.Dial("tcp", s.GetAddress(), s.GetClientConfig())
+	g.Expect(err).To(Succeed())
+	defer func() { _ = client.Close() }()
+
+	session, err := client.NewSession()
+	g.Expect(err).To(Succeed())
+	g.Expect(session.Setenv("K", "V")).ToNot(Succeed())
+	g.Expect(session.Run("echo out; echo err >&2; exit 3")).To(MatchError("Process exited with status 3"))
+
+	session, err = client.NewSession()
+	g.Expect(err).To(Succeed())
+	out, err := session.CombinedOutput("echo out; echo err >&2")
+	g.Expect(err).To(Succeed())
+	g.Expect(string(out)).To(ContainSubstring("out\n"))
+	g.Expect(string(out)).To(ContainSubstring("err\n"))
+
+	session, err = client.NewSession()
+	g.Expect(err).To(Succeed())
+	g.Expect(session.Start("kill -USR1 $$; sleep 10")).To(Succeed())
+	err = session.Wait()
+	g.Expect(err).To(MatchError("Process exited with status 128 from signal USR1"))
+
+	eErr, ok := errorz.As[*ssh.ExitError](err)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(eErr.Signal()).To(Equal(string(ssh.SIGUSR1)))
+
+	_, _, err = client.OpenChannel("unknown", nil)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = client.Dial("tcp", "127.0.0.1:1")
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(s.GetHistory()).To(Equal([]string{
+		"echo out; echo err >&2; exit 3",
+		"echo out; echo err >&2",
+		"kill -USR1 $$; sleep 10",
+	}))
+
+	g.Expect(s.Close()).To(Succeed())
+
+	_, err = client.NewSession()
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = ssh.Dial("tcp", s.GetAddress(), s.GetClientConfig())
+	g.Expect(err).To(HaveOccurred())
+}